@@ -4,16 +4,20 @@ import (
 	"context"
 	"fmt"
 	"livoir-blog/internal/app"
+	"livoir-blog/internal/worker"
 	"livoir-blog/pkg/auth"
 	"livoir-blog/pkg/cache"
 	"livoir-blog/pkg/database"
+	"livoir-blog/pkg/highlight"
 	"livoir-blog/pkg/jwt"
 	"livoir-blog/pkg/logger"
 	"livoir-blog/pkg/opentelemetry"
+	"livoir-blog/pkg/sanitize"
 	"net/http"
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -116,12 +120,79 @@ func main() {
 		return
 	}
 
-	router, err := app.SetupRouter(db, repoProvider, encryptionKey, accessTokenExpiration, refreshTokenExpiration)
+	viper.SetDefault("content.highlight_style", "github")
+	viper.SetDefault("content.sanitize.body.base", sanitize.BaseUGC)
+	viper.SetDefault("content.sanitize.title.base", sanitize.BaseStrict)
+	viper.SetDefault("content.excerpt_length", 200)
+	viper.SetDefault("content.search_language", "english")
+	viper.SetDefault("content.suggest_cache_ttl", "30s")
+	contentConfig := app.ContentConfig{
+		HighlightStyle:  viper.GetString("content.highlight_style"),
+		ExcerptLength:   viper.GetInt("content.excerpt_length"),
+		SearchLanguage:  viper.GetString("content.search_language"),
+		SuggestCacheTTL: viper.GetDuration("content.suggest_cache_ttl"),
+	}
+	if err := viper.UnmarshalKey("content.sanitize.body", &contentConfig.BodyPolicy); err != nil {
+		logger.Log.Error("Invalid content body sanitize configuration", zap.Error(err))
+		return
+	}
+	if err := viper.UnmarshalKey("content.sanitize.title", &contentConfig.TitlePolicy); err != nil {
+		logger.Log.Error("Invalid content title sanitize configuration", zap.Error(err))
+		return
+	}
+
+	viper.SetDefault("posts.edit_lease_ttl", "2m")
+	viper.SetDefault("posts.autosave_limit", 20)
+	viper.SetDefault("posts.autosave_ttl", "168h")
+	editingConfig := app.EditingConfig{
+		LeaseTTL:      viper.GetDuration("posts.edit_lease_ttl"),
+		AutosaveLimit: viper.GetInt("posts.autosave_limit"),
+		AutosaveTTL:   viper.GetDuration("posts.autosave_ttl"),
+	}
+
+	// The HTTP handlers and the background workers share one post usecase
+	highlighter, err := highlight.New(contentConfig.HighlightStyle)
+	if err != nil {
+		logger.Log.Error("Failed to initialize highlighter", zap.Error(err))
+		return
+	}
+	postUsecase, err := app.NewPostUsecase(repoProvider, highlighter, contentConfig)
+	if err != nil {
+		logger.Log.Error("Failed to initialize post usecase", zap.Error(err))
+		return
+	}
+
+	// Search documents built with another language are rebuilt before serving
+	reindexed, err := postUsecase.ReindexSearch(context.Background())
+	if err != nil {
+		logger.Log.Error("Failed to reindex posts for search", zap.Error(err))
+		return
+	}
+	if reindexed > 0 {
+		logger.Log.Info("Reindexed posts for search", zap.Int("count", reindexed))
+	}
+
+	router, err := app.SetupRouter(db, repoProvider, postUsecase, highlighter, encryptionKey, accessTokenExpiration, refreshTokenExpiration, contentConfig, editingConfig)
 	if err != nil {
 		logger.Log.Error("Failed to setup router", zap.Error(err))
 		return
 	}
 
+	viper.SetDefault("worker.scheduled_publish_interval", "30s")
+	scheduledPublisher, err := worker.NewScheduledPublisher(postUsecase, viper.GetDuration("worker.scheduled_publish_interval"))
+	if err != nil {
+		logger.Log.Error("Failed to setup scheduled publisher", zap.Error(err))
+		return
+	}
+	viper.SetDefault("worker.trash_purge_interval", "1h")
+	viper.SetDefault("posts.trash_retention_days", 30)
+	trashRetention := time.Duration(viper.GetInt("posts.trash_retention_days")) * 24 * time.Hour
+	trashPurger, err := worker.NewTrashPurger(postUsecase, trashRetention, viper.GetDuration("worker.trash_purge_interval"))
+	if err != nil {
+		logger.Log.Error("Failed to setup trash purger", zap.Error(err))
+		return
+	}
+
 	port := viper.GetString("server.port")
 	if port == "" {
 		logger.Log.Error("Server port not specified in configuration")
@@ -147,6 +218,24 @@ func main() {
 
 	logger.Log.Info("Server is running on port " + port)
 
+	// Start background workers in goroutines
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	workers.Add(2)
+	go func() {
+		defer workers.Done()
+		scheduledPublisher.Run(workerCtx)
+	}()
+	go func() {
+		defer workers.Done()
+		trashPurger.Run(workerCtx)
+	}()
+	workersDone := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(workersDone)
+	}()
+
 	// Wait for shutdown signal
 	<-quit
 	logger.Log.Info("Shutting down server...")
@@ -160,6 +249,14 @@ func main() {
 		logger.Log.Error("Server forced to shutdown:", zap.Error(err))
 	}
 
+	// Stop background workers before closing their connections
+	stopWorkers()
+	select {
+	case <-workersDone:
+	case <-ctx.Done():
+		logger.Log.Error("Background workers did not stop in time")
+	}
+
 	// Close database connection
 	if err := db.Close(); err != nil {
 		logger.Log.Error("Error closing database connection:", zap.Error(err))
@@ -22,8 +22,13 @@ type RepositoryProvider struct {
 	AdministratorSessionRepository domain.AdministratorSessionRepository
 	PostRepository                 domain.PostRepository
 	PostVersionRepository          domain.PostVersionRepository
+	PostSlugRepository             domain.PostSlugRepository
 	CategoryRepository             domain.CategoryRepository
 	CacheRepository                domain.CacheRepository
+	EditLeaseRepository            domain.EditLeaseRepository
+	AutosaveRepository             domain.AutosaveRepository
+	SearchRepository               domain.SearchRepository
+	TagRepository                  domain.TagRepository
 }
 
 func NewRepositoryProvider(db *sql.DB, cache *redis.Client, oauthGoogleConfig, oauthDiscordConfig *oauth2.Config, privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey) (*RepositoryProvider, error) {
@@ -37,6 +42,11 @@ func NewRepositoryProvider(db *sql.DB, cache *redis.Client, oauthGoogleConfig, o
 		logger.Log.Error("Failed to initialize post version repository", zap.Error(err))
 		return nil, err
 	}
+	postSlugRepo, err := repository.NewPostSlugRepository(db)
+	if err != nil {
+		logger.Log.Error("Failed to initialize post slug repository", zap.Error(err))
+		return nil, err
+	}
 	oauthGoogleRepo, err := repository.NewOauthGoogleRepository(oauthGoogleConfig)
 	if err != nil {
 		logger.Log.Error("Failed to initialize oauth repository", zap.Error(err))
@@ -77,6 +87,26 @@ func NewRepositoryProvider(db *sql.DB, cache *redis.Client, oauthGoogleConfig, o
 		logger.Log.Error("Failed to initialize cache repository", zap.Error(err))
 		return nil, err
 	}
+	editLeaseRepo, err := repository.NewEditLeaseRepositoryRedis(cache)
+	if err != nil {
+		logger.Log.Error("Failed to initialize edit lease repository", zap.Error(err))
+		return nil, err
+	}
+	autosaveRepo, err := repository.NewAutosaveRepositoryRedis(cache)
+	if err != nil {
+		logger.Log.Error("Failed to initialize autosave repository", zap.Error(err))
+		return nil, err
+	}
+	searchRepo, err := repository.NewSearchRepository(db)
+	if err != nil {
+		logger.Log.Error("Failed to initialize search repository", zap.Error(err))
+		return nil, err
+	}
+	tagRepo, err := repository.NewTagRepository(db)
+	if err != nil {
+		logger.Log.Error("Failed to initialize tag repository", zap.Error(err))
+		return nil, err
+	}
 
 	return &RepositoryProvider{
 		transactor,
@@ -87,8 +117,13 @@ func NewRepositoryProvider(db *sql.DB, cache *redis.Client, oauthGoogleConfig, o
 		administratorSessionRepo,
 		postRepo,
 		postVersionRepo,
+		postSlugRepo,
 		categoryRepo,
 		cacheRepo,
+		editLeaseRepo,
+		autosaveRepo,
+		searchRepo,
+		tagRepo,
 	}, nil
 }
 
@@ -3,16 +3,36 @@ package app
 import (
 	"database/sql"
 	"livoir-blog/internal/delivery/http"
+	"livoir-blog/internal/domain"
 	"livoir-blog/internal/usecase"
 	"livoir-blog/pkg/common"
+	"livoir-blog/pkg/highlight"
 	"livoir-blog/pkg/logger"
+	"livoir-blog/pkg/sanitize"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
-func SetupRouter(db *sql.DB, repoProvider *RepositoryProvider, encryptionKey string, accessTokenExpiration time.Duration, refreshTokenExpiration time.Duration) (*gin.Engine, error) {
+// ContentConfig controls how post content is rendered and sanitized.
+type ContentConfig struct {
+	HighlightStyle  string
+	BodyPolicy      sanitize.PolicyConfig
+	TitlePolicy     sanitize.PolicyConfig
+	ExcerptLength   int
+	SearchLanguage  string
+	SuggestCacheTTL time.Duration
+}
+
+// EditingConfig controls edit leases and autosaves of posts.
+type EditingConfig struct {
+	LeaseTTL      time.Duration
+	AutosaveLimit int
+	AutosaveTTL   time.Duration
+}
+
+func SetupRouter(db *sql.DB, repoProvider *RepositoryProvider, postUsecase domain.PostUsecase, highlighter *highlight.Highlighter, encryptionKey string, accessTokenExpiration time.Duration, refreshTokenExpiration time.Duration, contentConfig ContentConfig, editingConfig EditingConfig) (*gin.Engine, error) {
 	if db == nil {
 		logger.Log.Error("Database connection is nil")
 		return nil, common.NewCustomError(500, "Database connection is nil")
@@ -27,9 +47,33 @@ func SetupRouter(db *sql.DB, repoProvider *RepositoryProvider, encryptionKey str
 		return nil, common.NewCustomError(500, "Encryption key is required")
 	}
 
-	postUsecase, err := usecase.NewPostUsecase(repoProvider.PostRepository, repoProvider.PostVersionRepository, repoProvider.Transactor)
+	if postUsecase == nil {
+		logger.Log.Error("Post usecase is nil")
+		return nil, common.NewCustomError(500, "Post usecase is required")
+	}
+	if highlighter == nil {
+		logger.Log.Error("Highlighter is nil")
+		return nil, common.NewCustomError(500, "Highlighter is required")
+	}
+
+	editLeaseUsecase, err := usecase.NewEditLeaseUsecase(repoProvider.EditLeaseRepository, repoProvider.PostRepository, repoProvider.AdministratorRepository, editingConfig.LeaseTTL)
+	if err != nil {
+		logger.Log.Error("Failed to initialize edit lease usecase", zap.Error(err))
+		return nil, err
+	}
+	autosaveUsecase, err := usecase.NewAutosaveUsecase(repoProvider.AutosaveRepository, repoProvider.PostRepository, repoProvider.AdministratorRepository, repoProvider.EditLeaseRepository, postUsecase, editingConfig.AutosaveLimit, editingConfig.AutosaveTTL)
+	if err != nil {
+		logger.Log.Error("Failed to initialize autosave usecase", zap.Error(err))
+		return nil, err
+	}
+	searchUsecase, err := usecase.NewSearchUsecase(repoProvider.SearchRepository, repoProvider.CacheRepository, contentConfig.SearchLanguage, contentConfig.SuggestCacheTTL)
+	if err != nil {
+		logger.Log.Error("Failed to initialize search usecase", zap.Error(err))
+		return nil, err
+	}
+	tagUsecase, err := usecase.NewTagUsecase(repoProvider.TagRepository)
 	if err != nil {
-		logger.Log.Error("Failed to initialize post usecase", zap.Error(err))
+		logger.Log.Error("Failed to initialize tag usecase", zap.Error(err))
 		return nil, err
 	}
 	categoryUsecase, err := usecase.NewCategoryUsecase(repoProvider.Transactor, repoProvider.CategoryRepository, repoProvider.PostVersionRepository)
@@ -49,16 +93,38 @@ func SetupRouter(db *sql.DB, repoProvider *RepositoryProvider, encryptionKey str
 		logger.Log.Error("Failed to initialize oauth usecase", zap.Error(err))
 		return nil, err
 	}
+	authMiddleware := http.NewAuthMiddleware(repoProvider.TokenRepository)
 	r := gin.New()
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
 	postsApi := r.Group("/posts")
 	{
-		http.NewPostHandler(postsApi, postUsecase)
+		http.NewPostHandler(postsApi, postUsecase, authMiddleware)
+		http.NewEditLeaseHandler(postsApi, editLeaseUsecase, authMiddleware)
+		http.NewAutosaveHandler(postsApi, autosaveUsecase, authMiddleware)
+	}
+	authorsApi := r.Group("/authors")
+	{
+		http.NewAuthorHandler(authorsApi, postUsecase)
+	}
+	searchApi := r.Group("/search")
+	{
+		http.NewSearchHandler(searchApi, searchUsecase)
 	}
 	categoriesApi := r.Group("/categories")
 	{
-		http.NewCategoryHandler(categoriesApi, categoryUsecase)
+		http.NewCategoryHandler(categoriesApi, categoryUsecase, postUsecase, authMiddleware)
+	}
+	tagsApi := r.Group("/tags")
+	{
+		http.NewTagHandler(tagsApi, tagUsecase, postUsecase)
+	}
+	stylesApi := r.Group("/styles")
+	{
+		if err := http.NewStyleHandler(stylesApi, highlighter); err != nil {
+			logger.Log.Error("Failed to initialize style handler", zap.Error(err))
+			return nil, err
+		}
 	}
 	auth := r.Group("/auth")
 	{
@@ -67,3 +133,13 @@ func SetupRouter(db *sql.DB, repoProvider *RepositoryProvider, encryptionKey str
 
 	return r, nil
 }
+
+// NewPostUsecase builds the post usecase shared by the HTTP handlers and the
+// background workers.
+func NewPostUsecase(repoProvider *RepositoryProvider, highlighter *highlight.Highlighter, contentConfig ContentConfig) (domain.PostUsecase, error) {
+	if repoProvider == nil {
+		logger.Log.Error("Repository provider is nil")
+		return nil, common.NewCustomError(500, "Repository provider is required")
+	}
+	return usecase.NewPostUsecase(repoProvider.PostRepository, repoProvider.PostVersionRepository, repoProvider.PostSlugRepository, repoProvider.CategoryRepository, repoProvider.TagRepository, repoProvider.AdministratorRepository, repoProvider.EditLeaseRepository, repoProvider.SearchRepository, repoProvider.Transactor, highlighter, contentConfig.BodyPolicy, contentConfig.TitlePolicy, contentConfig.ExcerptLength, contentConfig.SearchLanguage)
+}
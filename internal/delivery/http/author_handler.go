@@ -0,0 +1,44 @@
+package http
+
+import (
+	"livoir-blog/internal/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type AuthorHandler struct {
+	PostUsecase domain.PostUsecase
+	tracer      trace.Tracer
+}
+
+func NewAuthorHandler(r *gin.RouterGroup, usecase domain.PostUsecase) {
+	handler := &AuthorHandler{
+		PostUsecase: usecase,
+		tracer:      otel.Tracer("author-handler"),
+	}
+	r.GET("/:id/posts", handler.ListAuthorPosts)
+}
+
+func (h *AuthorHandler) ListAuthorPosts(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "ListAuthorPosts")
+	defer span.End()
+	id := c.Param("id")
+	if !isValidAdministratorID(id) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid author ID"})
+		return
+	}
+	request, err := parsePostListRequest(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	response, err := h.PostUsecase.ListByAuthor(ctx, id, request)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"livoir-blog/internal/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type AutosaveHandler struct {
+	AutosaveUsecase domain.AutosaveUsecase
+	tracer          trace.Tracer
+}
+
+func NewAutosaveHandler(r *gin.RouterGroup, usecase domain.AutosaveUsecase, authMiddleware gin.HandlerFunc) {
+	handler := &AutosaveHandler{
+		AutosaveUsecase: usecase,
+		tracer:          otel.Tracer("autosave-handler"),
+	}
+	r.GET("/:id/autosaves", authMiddleware, handler.ListAutosaves)
+	r.POST("/:id/autosaves", authMiddleware, handler.SaveAutosave)
+	r.POST("/:id/autosaves/:autosave_id/promote", authMiddleware, handler.PromoteAutosave)
+	r.DELETE("/:id/autosaves", authMiddleware, handler.DiscardAutosaves)
+}
+
+func (h *AutosaveHandler) ListAutosaves(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "ListAutosaves")
+	defer span.End()
+	id := c.Param("id")
+	if !isValidID(id) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	response, err := h.AutosaveUsecase.List(ctx, id, editorEmail(c))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AutosaveHandler) SaveAutosave(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "SaveAutosave")
+	defer span.End()
+	id := c.Param("id")
+	if !isValidID(id) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	var request domain.UpdatePostDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateUpdatePostDTO(&request); err != nil {
+		handleError(c, err)
+		return
+	}
+	request.EditorEmail = editorEmail(c)
+	response, err := h.AutosaveUsecase.Save(ctx, id, &request)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *AutosaveHandler) PromoteAutosave(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "PromoteAutosave")
+	defer span.End()
+	id := c.Param("id")
+	if !isValidID(id) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	autosaveID := c.Param("autosave_id")
+	if !isValidID(autosaveID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid autosave ID"})
+		return
+	}
+	request := domain.PromoteAutosaveDTO{EditorEmail: editorEmail(c)}
+	var ok bool
+	request.IfMatch, ok = ifMatch(c)
+	if !ok {
+		return
+	}
+	response, err := h.AutosaveUsecase.Promote(ctx, id, autosaveID, &request)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.Header("ETag", response.ETag)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AutosaveHandler) DiscardAutosaves(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "DiscardAutosaves")
+	defer span.End()
+	id := c.Param("id")
+	if !isValidID(id) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	if err := h.AutosaveUsecase.Discard(ctx, id, editorEmail(c)); err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Autosaves discarded"})
+}
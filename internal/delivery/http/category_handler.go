@@ -14,17 +14,92 @@ import (
 
 type CategoryHandler struct {
 	CategoryUsecase domain.CategoryUsecase
+	PostUsecase     domain.PostUsecase
 	tracer          trace.Tracer
 }
 
-func NewCategoryHandler(r *gin.RouterGroup, usecase domain.CategoryUsecase) {
+func NewCategoryHandler(r *gin.RouterGroup, usecase domain.CategoryUsecase, postUsecase domain.PostUsecase, authMiddleware gin.HandlerFunc) {
 	handler := &CategoryHandler{
 		CategoryUsecase: usecase,
+		PostUsecase:     postUsecase,
 		tracer:          otel.Tracer("category-handler"),
 	}
+	r.GET("", handler.ListCategories)
+	r.GET("/tree", handler.GetCategoryTree)
+	r.GET("/:id", handler.GetCategory)
+	r.GET("/:id/posts", handler.ListCategoryPosts)
 	r.POST("", handler.CreateCategory)
 	r.PUT("/:id", handler.UpdateCategory)
+	r.DELETE("/:id", authMiddleware, handler.DeleteCategory)
 	r.POST("/attach", handler.AttachCategoryToPostVersion)
+	r.POST("/replace", authMiddleware, handler.ReplacePostVersionCategories)
+	r.POST("/detach", authMiddleware, handler.DetachCategoryFromPostVersion)
+}
+
+func (h *CategoryHandler) ListCategories(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "ListCategories")
+	defer span.End()
+	request := domain.CategoryListRequestDTO{
+		Sort: c.DefaultQuery("sort", domain.CategorySortName),
+	}
+	if request.Sort != domain.CategorySortName && request.Sort != domain.CategorySortCount {
+		handleError(c, common.NewCustomError(http.StatusBadRequest, "sort must be name or count"))
+		return
+	}
+	response, err := h.CategoryUsecase.List(ctx, &request)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *CategoryHandler) GetCategoryTree(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "GetCategoryTree")
+	defer span.End()
+	response, err := h.CategoryUsecase.GetTree(ctx)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *CategoryHandler) GetCategory(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "GetCategory")
+	defer span.End()
+	id, ok := h.validateAndGetCategoryID(c)
+	if !ok {
+		handleError(c, common.NewCustomError(http.StatusBadRequest, "invalid category id"))
+		return
+	}
+	response, err := h.CategoryUsecase.GetByID(ctx, id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *CategoryHandler) ListCategoryPosts(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "ListCategoryPosts")
+	defer span.End()
+	id, ok := h.validateAndGetCategoryID(c)
+	if !ok {
+		handleError(c, common.NewCustomError(http.StatusBadRequest, "invalid category id"))
+		return
+	}
+	request, err := parsePostListRequest(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	response, err := h.PostUsecase.ListByCategory(ctx, id, request)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 func (h *CategoryHandler) CreateCategory(c *gin.Context) {
@@ -72,6 +147,21 @@ func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "DeleteCategory")
+	defer span.End()
+	id, ok := h.validateAndGetCategoryID(c)
+	if !ok {
+		handleError(c, common.NewCustomError(http.StatusBadRequest, "invalid category id"))
+		return
+	}
+	if err := h.CategoryUsecase.Delete(ctx, id); err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "category deleted successfully"})
+}
+
 func (h *CategoryHandler) AttachCategoryToPostVersion(c *gin.Context) {
 	ctx, span := h.tracer.Start(c.Request.Context(), "AttachCategoryToPostVersion")
 	defer span.End()
@@ -91,6 +181,48 @@ func (h *CategoryHandler) AttachCategoryToPostVersion(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "category attached to post version successfully"})
 }
 
+func (h *CategoryHandler) ReplacePostVersionCategories(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "ReplacePostVersionCategories")
+	defer span.End()
+	var request domain.ReplacePostVersionCategoriesRequestDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handleError(c, common.NewCustomError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	if err := h.validateReplacePostVersionCategoriesRequestDTO(&request); err != nil {
+		handleError(c, err)
+		return
+	}
+	if err := h.CategoryUsecase.ReplaceOnPostVersion(ctx, &request); err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "post version categories replaced successfully"})
+}
+
+func (h *CategoryHandler) DetachCategoryFromPostVersion(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "DetachCategoryFromPostVersion")
+	defer span.End()
+	var request domain.DetachCategoryFromPostVersionRequestDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handleError(c, common.NewCustomError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	if request.PostVersionID == "" || !isValidID(request.PostVersionID) {
+		handleError(c, common.NewCustomError(http.StatusBadRequest, "invalid post version id"))
+		return
+	}
+	if request.CategoryID == "" || !isValidID(request.CategoryID) {
+		handleError(c, common.NewCustomError(http.StatusBadRequest, "invalid category id"))
+		return
+	}
+	if err := h.CategoryUsecase.DetachFromPostVersion(ctx, &request); err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "category detached from post version successfully"})
+}
+
 func (h *CategoryHandler) validateCategoryRequestDTO(request *domain.CategoryRequestDTO) error {
 	missingFields := []string{}
 	if strings.TrimSpace(request.Name) == "" {
@@ -99,6 +231,9 @@ func (h *CategoryHandler) validateCategoryRequestDTO(request *domain.CategoryReq
 	if len(missingFields) > 0 {
 		return common.NewCustomError(http.StatusBadRequest, fmt.Sprintf("%s required", strings.Join(missingFields, " and ")))
 	}
+	if request.ParentID != nil && *request.ParentID != "" && !isValidID(*request.ParentID) {
+		return common.NewCustomError(http.StatusBadRequest, "invalid parent id")
+	}
 	return nil
 }
 
@@ -138,3 +273,24 @@ func (h *CategoryHandler) validateAttachCategoryToPostVersionRequestDTO(request
 
 	return nil
 }
+
+func (h *CategoryHandler) validateReplacePostVersionCategoriesRequestDTO(request *domain.ReplacePostVersionCategoriesRequestDTO) error {
+	errors := []string{}
+	if request.PostVersionID == "" || !isValidID(request.PostVersionID) {
+		errors = append(errors, "invalid post version id")
+	}
+	seenIDs := make(map[string]bool)
+	for _, categoryID := range request.CategoryIDs {
+		if seenIDs[categoryID] {
+			errors = append(errors, fmt.Sprintf("duplicate category id: %s", categoryID))
+		}
+		if !isValidID(categoryID) {
+			errors = append(errors, fmt.Sprintf("invalid category id: %s", categoryID))
+		}
+		seenIDs[categoryID] = true
+	}
+	if len(errors) > 0 {
+		return common.NewCustomError(http.StatusBadRequest, strings.Join(errors, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"livoir-blog/internal/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type EditLeaseHandler struct {
+	EditLeaseUsecase domain.EditLeaseUsecase
+	tracer           trace.Tracer
+}
+
+func NewEditLeaseHandler(r *gin.RouterGroup, usecase domain.EditLeaseUsecase, authMiddleware gin.HandlerFunc) {
+	handler := &EditLeaseHandler{
+		EditLeaseUsecase: usecase,
+		tracer:           otel.Tracer("edit-lease-handler"),
+	}
+	r.GET("/:id/lease", authMiddleware, handler.GetEditLease)
+	r.POST("/:id/lease", authMiddleware, handler.AcquireEditLease)
+	r.PUT("/:id/lease", authMiddleware, handler.RenewEditLease)
+	r.DELETE("/:id/lease", authMiddleware, handler.ReleaseEditLease)
+}
+
+func (h *EditLeaseHandler) GetEditLease(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "GetEditLease")
+	defer span.End()
+	id := c.Param("id")
+	if !isValidID(id) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	lease, err := h.EditLeaseUsecase.Get(ctx, id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, lease)
+}
+
+func (h *EditLeaseHandler) AcquireEditLease(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AcquireEditLease")
+	defer span.End()
+	id := c.Param("id")
+	if !isValidID(id) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	response, err := h.EditLeaseUsecase.Acquire(ctx, id, &domain.EditLeaseRequestDTO{EditorEmail: editorEmail(c)})
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *EditLeaseHandler) RenewEditLease(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "RenewEditLease")
+	defer span.End()
+	id := c.Param("id")
+	if !isValidID(id) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	response, err := h.EditLeaseUsecase.Renew(ctx, id, &domain.EditLeaseRequestDTO{EditorEmail: editorEmail(c)})
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *EditLeaseHandler) ReleaseEditLease(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "ReleaseEditLease")
+	defer span.End()
+	id := c.Param("id")
+	if !isValidID(id) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	err := h.EditLeaseUsecase.Release(ctx, id, &domain.EditLeaseRequestDTO{
+		EditorEmail: editorEmail(c),
+		Force:       c.Query("force") == "true",
+	})
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Edit lease released"})
+}
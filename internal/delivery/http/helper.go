@@ -7,7 +7,9 @@ import (
 	"livoir-blog/pkg/common"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"slices"
 
@@ -37,12 +39,136 @@ func validateCreatePostDTO(request *domain.CreatePostDTO) error {
 	return nil
 }
 
+const (
+	defaultListLimit = 10
+	maxListLimit     = 100
+)
+
+func parsePostListRequest(c *gin.Context) (*domain.PostListRequestDTO, error) {
+	limit, err := parseLimit(c.Query("limit"))
+	if err != nil {
+		return nil, err
+	}
+	request := &domain.PostListRequestDTO{
+		Cursor:             c.Query("cursor"),
+		CategoryID:         c.Query("category_id"),
+		IncludeDescendants: c.Query("include_descendants") == "true",
+		Limit:              limit,
+	}
+	if request.CategoryID != "" && !isValidID(request.CategoryID) {
+		return nil, common.NewCustomError(http.StatusBadRequest, "invalid category id")
+	}
+	request.PublishedAfter, err = parseTimeQuery(c.Query("published_after"))
+	if err != nil {
+		return nil, common.NewCustomError(http.StatusBadRequest, "invalid published_after")
+	}
+	request.PublishedBefore, err = parseTimeQuery(c.Query("published_before"))
+	if err != nil {
+		return nil, common.NewCustomError(http.StatusBadRequest, "invalid published_before")
+	}
+	return request, nil
+}
+
+func parsePostVersionDiffRequest(c *gin.Context) (*domain.PostVersionDiffRequestDTO, error) {
+	request := &domain.PostVersionDiffRequestDTO{
+		Granularity: c.DefaultQuery("granularity", domain.DiffGranularityLine),
+		Format:      c.DefaultQuery("format", domain.DiffFormatJSON),
+	}
+	var err error
+	request.FromVersion, err = parseVersionNumberQuery(c.Query("from"))
+	if err != nil {
+		return nil, common.NewCustomError(http.StatusBadRequest, "invalid from version")
+	}
+	request.ToVersion, err = parseVersionNumberQuery(c.Query("to"))
+	if err != nil {
+		return nil, common.NewCustomError(http.StatusBadRequest, "invalid to version")
+	}
+	if request.Granularity != domain.DiffGranularityLine && request.Granularity != domain.DiffGranularityWord {
+		return nil, common.NewCustomError(http.StatusBadRequest, "granularity must be line or word")
+	}
+	if request.Format != domain.DiffFormatJSON && request.Format != domain.DiffFormatUnified {
+		return nil, common.NewCustomError(http.StatusBadRequest, "format must be json or unified")
+	}
+	return request, nil
+}
+
+func parseVersionNumberQuery(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	versionNumber, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || versionNumber < 1 {
+		return 0, errors.New("invalid version number")
+	}
+	return versionNumber, nil
+}
+
+func parseLimit(value string) (int, error) {
+	if value == "" {
+		return defaultListLimit, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > maxListLimit {
+		return 0, common.NewCustomError(http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxListLimit))
+	}
+	return limit, nil
+}
+
+// parseTimeQuery accepts either an RFC 3339 timestamp or a plain date.
+func parseTimeQuery(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t, err = time.Parse(time.DateOnly, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &t, nil
+}
+
 func isValidID(id string) bool {
 	_, err := ulid.Parse(id)
 	return err == nil
 }
 
+// isValidAdministratorID only checks the length of the key, since
+// administrators are not always keyed by ULIDs.
+func isValidAdministratorID(id string) bool {
+	return id != "" && len(id) <= ulid.EncodedSize
+}
+
+// ifMatch returns the If-Match header, answering 428 Precondition Required
+// when it is missing so edits never silently overwrite each other.
+func ifMatch(c *gin.Context) (string, bool) {
+	value := c.GetHeader("If-Match")
+	if value == "" {
+		handleError(c, common.NewCustomError(http.StatusPreconditionRequired, "If-Match header is required"))
+		return "", false
+	}
+	return value, true
+}
+
 func handleError(c *gin.Context, err error) {
+	var staleErr *domain.StaleVersionError
+	if errors.As(err, &staleErr) {
+		c.Header("ETag", staleErr.Current.ETag)
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": staleErr.Error(), "current": staleErr.Current})
+		return
+	}
+	var conflictErr *domain.MergeConflictError
+	if errors.As(err, &conflictErr) {
+		c.Header("ETag", conflictErr.Current.ETag)
+		c.JSON(http.StatusConflict, gin.H{"error": conflictErr.Error(), "current": conflictErr.Current, "conflicts": conflictErr.Conflicts})
+		return
+	}
+	var leaseErr *domain.EditLeaseHeldError
+	if errors.As(err, &leaseErr) {
+		c.JSON(http.StatusLocked, gin.H{"error": leaseErr.Error(), "lease": leaseErr.Lease})
+		return
+	}
 	if customErr, ok := err.(*common.CustomError); ok {
 		switch customErr.StatusCode {
 		case http.StatusNotFound:
@@ -55,6 +181,8 @@ func handleError(c *gin.Context, err error) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": customErr.Message})
 		case http.StatusUnauthorized:
 			c.JSON(http.StatusUnauthorized, gin.H{"error": customErr.Message})
+		case http.StatusPreconditionRequired:
+			c.JSON(http.StatusPreconditionRequired, gin.H{"error": customErr.Message})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"livoir-blog/internal/domain"
+	"livoir-blog/pkg/common"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const tokenDataKey = "token_data"
+
+// NewAuthMiddleware rejects requests that do not carry a valid access token,
+// either as a bearer token or in the access_token cookie set on login.
+func NewAuthMiddleware(tokenRepo domain.TokenRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := accessToken(c)
+		if token == "" {
+			handleError(c, common.NewCustomError(http.StatusUnauthorized, "access token is missing"))
+			c.Abort()
+			return
+		}
+		validateToken(c, tokenRepo, token)
+	}
+}
+
+func accessToken(c *gin.Context) string {
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if token == "" {
+		token, _ = c.Cookie("access_token")
+	}
+	return token
+}
+
+func validateToken(c *gin.Context, tokenRepo domain.TokenRepository, token string) {
+	tokenData, err := tokenRepo.Validate(c.Request.Context(), token)
+	if err != nil {
+		handleError(c, common.ErrInvalidToken)
+		c.Abort()
+		return
+	}
+	c.Set(tokenDataKey, tokenData)
+	c.Next()
+}
+
+// editorEmail returns the email of the authenticated administrator, or an
+// empty string for anonymous requests.
+func editorEmail(c *gin.Context) string {
+	value, _ := c.Get(tokenDataKey)
+	tokenData, ok := value.(*domain.TokenData)
+	if !ok {
+		return ""
+	}
+	return tokenData.Email
+}
@@ -2,6 +2,9 @@ package http
 
 import (
 	"net/http"
+	"net/url"
+	"path"
+	"strconv"
 
 	"livoir-blog/internal/domain"
 
@@ -15,16 +18,32 @@ type PostHandler struct {
 	tracer      trace.Tracer
 }
 
-func NewPostHandler(r *gin.RouterGroup, usecase domain.PostUsecase) {
+func NewPostHandler(r *gin.RouterGroup, usecase domain.PostUsecase, authMiddleware gin.HandlerFunc) {
 	handler := &PostHandler{
 		PostUsecase: usecase,
 		tracer:      otel.Tracer("post-handler"),
 	}
+	r.GET("", handler.ListPosts)
+	r.GET("/trash", authMiddleware, handler.ListDeletedPosts)
 	r.GET("/:id", handler.GetPost)
-	r.POST("", handler.CreatePost)
-	r.PUT("/:id", handler.UpdatePost)
-	r.POST("/:id/publish", handler.PublishPost)
-	r.DELETE("/:id", handler.DeletePostVersion)
+	r.GET("/:id/draft", authMiddleware, handler.GetPostDraft)
+	r.GET("/slug/:slug", handler.GetPostBySlug)
+	r.GET("/:id/versions", authMiddleware, handler.ListPostVersions)
+	r.GET("/:id/versions/:number", authMiddleware, handler.GetPostVersion)
+	r.GET("/:id/diff", authMiddleware, handler.DiffPostVersions)
+	r.POST("", authMiddleware, handler.CreatePost)
+	r.PUT("/:id", authMiddleware, handler.UpdatePost)
+	r.POST("/:id/publish", authMiddleware, handler.PublishPost)
+	r.POST("/:id/unpublish", authMiddleware, handler.UnpublishPost)
+	r.POST("/:id/archive", authMiddleware, handler.ArchivePost)
+	r.POST("/:id/unarchive", authMiddleware, handler.UnarchivePost)
+	r.POST("/:id/revert", authMiddleware, handler.RevertPost)
+	r.PUT("/:id/schedule", authMiddleware, handler.SchedulePost)
+	r.DELETE("/:id/schedule", authMiddleware, handler.CancelScheduledPost)
+	r.DELETE("/:id", authMiddleware, handler.DeletePost)
+	r.POST("/:id/restore", authMiddleware, handler.RestorePost)
+	r.PUT("/:id/authors", authMiddleware, handler.SetPostAuthors)
+	r.DELETE("/:id/draft", authMiddleware, handler.DeletePostVersion)
 }
 
 func (h *PostHandler) validateAndGetPostID(c *gin.Context) (string, bool) {
@@ -35,6 +54,14 @@ func (h *PostHandler) validateAndGetPostID(c *gin.Context) (string, bool) {
 	return id, true
 }
 
+func (h *PostHandler) validateAndGetVersionNumber(c *gin.Context, param string) (int64, bool) {
+	versionNumber, err := strconv.ParseInt(c.Param(param), 10, 64)
+	if err != nil || versionNumber < 1 {
+		return 0, false
+	}
+	return versionNumber, true
+}
+
 func (h *PostHandler) GetPost(c *gin.Context) {
 	ctx, span := h.tracer.Start(c.Request.Context(), "GetPost")
 	defer span.End()
@@ -48,9 +75,145 @@ func (h *PostHandler) GetPost(c *gin.Context) {
 		handleError(c, err)
 		return
 	}
+	c.Header("ETag", post.ETag)
+	c.JSON(http.StatusOK, post)
+}
+
+func (h *PostHandler) GetPostDraft(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "GetPostDraft")
+	defer span.End()
+	id, ok := h.validateAndGetPostID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	post, err := h.PostUsecase.GetDraftByID(ctx, id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.Header("ETag", post.ETag)
 	c.JSON(http.StatusOK, post)
 }
 
+func (h *PostHandler) GetPostBySlug(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "GetPostBySlug")
+	defer span.End()
+	postSlug := c.Param("slug")
+	if postSlug == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post slug"})
+		return
+	}
+	post, currentSlug, err := h.PostUsecase.GetBySlug(ctx, postSlug)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	if currentSlug != "" {
+		c.Redirect(http.StatusMovedPermanently, path.Join(path.Dir(c.Request.URL.Path), url.PathEscape(currentSlug)))
+		return
+	}
+	c.Header("ETag", post.ETag)
+	c.JSON(http.StatusOK, post)
+}
+
+func (h *PostHandler) ListPosts(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "ListPosts")
+	defer span.End()
+	request, err := parsePostListRequest(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	response, err := h.PostUsecase.List(ctx, request)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *PostHandler) ListDeletedPosts(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "ListDeletedPosts")
+	defer span.End()
+	limit, err := parseLimit(c.Query("limit"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	response, err := h.PostUsecase.ListDeleted(ctx, &domain.PostListRequestDTO{
+		Cursor: c.Query("cursor"),
+		Limit:  limit,
+	})
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *PostHandler) ListPostVersions(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "ListPostVersions")
+	defer span.End()
+	id, ok := h.validateAndGetPostID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	response, err := h.PostUsecase.ListVersions(ctx, id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *PostHandler) GetPostVersion(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "GetPostVersion")
+	defer span.End()
+	id, ok := h.validateAndGetPostID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	versionNumber, ok := h.validateAndGetVersionNumber(c, "number")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version number"})
+		return
+	}
+	response, err := h.PostUsecase.GetVersion(ctx, id, versionNumber)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *PostHandler) DiffPostVersions(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "DiffPostVersions")
+	defer span.End()
+	id, ok := h.validateAndGetPostID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	request, err := parsePostVersionDiffRequest(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	response, err := h.PostUsecase.DiffVersions(ctx, id, request)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	if request.Format == domain.DiffFormatUnified {
+		c.String(http.StatusOK, response.Unified)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
 func (h *PostHandler) CreatePost(c *gin.Context) {
 	ctx, span := h.tracer.Start(c.Request.Context(), "CreatePost")
 	defer span.End()
@@ -63,11 +226,13 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 		handleError(c, err)
 		return
 	}
+	post.EditorEmail = editorEmail(c)
 	response, err := h.PostUsecase.Create(ctx, &post)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
+	c.Header("ETag", response.ETag)
 	c.JSON(http.StatusCreated, response)
 }
 
@@ -88,11 +253,17 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 		handleError(c, err)
 		return
 	}
+	post.EditorEmail = editorEmail(c)
+	post.IfMatch, ok = ifMatch(c)
+	if !ok {
+		return
+	}
 	response, err := h.PostUsecase.Update(ctx, id, &post)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
+	c.Header("ETag", response.ETag)
 	c.JSON(http.StatusOK, response)
 }
 
@@ -104,7 +275,165 @@ func (h *PostHandler) PublishPost(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
 		return
 	}
-	response, err := h.PostUsecase.Publish(ctx, id)
+	var request domain.PublishPostDTO
+	request.IfMatch, ok = ifMatch(c)
+	if !ok {
+		return
+	}
+	response, err := h.PostUsecase.Publish(ctx, id, &request)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.Header("ETag", response.ETag)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *PostHandler) UnpublishPost(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "UnpublishPost")
+	defer span.End()
+	id, ok := h.validateAndGetPostID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	response, err := h.PostUsecase.Unpublish(ctx, id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *PostHandler) ArchivePost(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "ArchivePost")
+	defer span.End()
+	id, ok := h.validateAndGetPostID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	response, err := h.PostUsecase.Archive(ctx, id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *PostHandler) UnarchivePost(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "UnarchivePost")
+	defer span.End()
+	id, ok := h.validateAndGetPostID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	response, err := h.PostUsecase.Unarchive(ctx, id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *PostHandler) RevertPost(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "RevertPost")
+	defer span.End()
+	id, ok := h.validateAndGetPostID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	var request domain.RevertPostDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if request.VersionNumber < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version number"})
+		return
+	}
+	request.EditorEmail = editorEmail(c)
+	request.IfMatch, ok = ifMatch(c)
+	if !ok {
+		return
+	}
+	response, err := h.PostUsecase.Revert(ctx, id, &request)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.Header("ETag", response.ETag)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *PostHandler) SchedulePost(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "SchedulePost")
+	defer span.End()
+	id, ok := h.validateAndGetPostID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	var request domain.SchedulePostDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if request.PublishAt.IsZero() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "publish_at is required"})
+		return
+	}
+	response, err := h.PostUsecase.Schedule(ctx, id, &request)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *PostHandler) CancelScheduledPost(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "CancelScheduledPost")
+	defer span.End()
+	id, ok := h.validateAndGetPostID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	response, err := h.PostUsecase.CancelSchedule(ctx, id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *PostHandler) DeletePost(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "DeletePost")
+	defer span.End()
+	id, ok := h.validateAndGetPostID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	response, err := h.PostUsecase.Delete(ctx, id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *PostHandler) RestorePost(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "RestorePost")
+	defer span.End()
+	id, ok := h.validateAndGetPostID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	response, err := h.PostUsecase.Restore(ctx, id)
 	if err != nil {
 		handleError(c, err)
 		return
@@ -127,3 +456,30 @@ func (h *PostHandler) DeletePostVersion(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Post version deleted"})
 }
+
+func (h *PostHandler) SetPostAuthors(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "SetPostAuthors")
+	defer span.End()
+	id, ok := h.validateAndGetPostID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	var request domain.SetPostAuthorsDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for _, author := range request.Authors {
+		if !isValidAdministratorID(author.AdministratorID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid administrator ID"})
+			return
+		}
+	}
+	response, err := h.PostUsecase.SetAuthors(ctx, id, &request)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
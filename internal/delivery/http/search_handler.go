@@ -0,0 +1,70 @@
+package http
+
+import (
+	"livoir-blog/internal/domain"
+	"livoir-blog/pkg/common"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type SearchHandler struct {
+	SearchUsecase domain.SearchUsecase
+	tracer        trace.Tracer
+}
+
+func NewSearchHandler(r *gin.RouterGroup, usecase domain.SearchUsecase) {
+	handler := &SearchHandler{
+		SearchUsecase: usecase,
+		tracer:        otel.Tracer("search-handler"),
+	}
+	r.GET("", handler.Search)
+	r.GET("/suggest", handler.Suggest)
+}
+
+func (h *SearchHandler) Search(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "Search")
+	defer span.End()
+	limit, err := parseLimit(c.Query("limit"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	request := &domain.SearchRequestDTO{
+		Query:      c.Query("q"),
+		CategoryID: c.Query("category_id"),
+		Cursor:     c.Query("cursor"),
+		Limit:      limit,
+	}
+	if request.CategoryID != "" && !isValidID(request.CategoryID) {
+		handleError(c, common.NewCustomError(http.StatusBadRequest, "invalid category id"))
+		return
+	}
+	response, err := h.SearchUsecase.Search(ctx, request)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *SearchHandler) Suggest(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "Suggest")
+	defer span.End()
+	limit, err := parseLimit(c.Query("limit"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	response, err := h.SearchUsecase.Suggest(ctx, &domain.SuggestRequestDTO{
+		Query: c.Query("q"),
+		Limit: limit,
+	})
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
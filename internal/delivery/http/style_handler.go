@@ -0,0 +1,31 @@
+package http
+
+import (
+	"livoir-blog/pkg/highlight"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StyleHandler struct {
+	highlightCSS string
+}
+
+func NewStyleHandler(r *gin.RouterGroup, highlighter *highlight.Highlighter) error {
+	css, err := highlighter.CSS()
+	if err != nil {
+		return err
+	}
+	handler := &StyleHandler{
+		highlightCSS: css,
+	}
+	r.GET("/highlight.css", handler.GetHighlightCSS)
+	return nil
+}
+
+// GetHighlightCSS serves the stylesheet for the classes added to highlighted
+// code blocks.
+func (h *StyleHandler) GetHighlightCSS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Data(http.StatusOK, "text/css; charset=utf-8", []byte(h.highlightCSS))
+}
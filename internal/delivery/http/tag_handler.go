@@ -0,0 +1,53 @@
+package http
+
+import (
+	"livoir-blog/internal/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type TagHandler struct {
+	TagUsecase  domain.TagUsecase
+	PostUsecase domain.PostUsecase
+	tracer      trace.Tracer
+}
+
+func NewTagHandler(r *gin.RouterGroup, tagUsecase domain.TagUsecase, postUsecase domain.PostUsecase) {
+	handler := &TagHandler{
+		TagUsecase:  tagUsecase,
+		PostUsecase: postUsecase,
+		tracer:      otel.Tracer("tag-handler"),
+	}
+	r.GET("", handler.ListTags)
+	r.GET("/:slug/posts", handler.ListTagPosts)
+}
+
+func (h *TagHandler) ListTags(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "ListTags")
+	defer span.End()
+	response, err := h.TagUsecase.List(ctx)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *TagHandler) ListTagPosts(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "ListTagPosts")
+	defer span.End()
+	request, err := parsePostListRequest(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	response, err := h.PostUsecase.ListByTag(ctx, c.Param("slug"), request)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
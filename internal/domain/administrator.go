@@ -16,5 +16,6 @@ type Administrator struct {
 
 type AdministratorRepository interface {
 	FindByEmail(ctx context.Context, email string) (*Administrator, error)
+	FindByID(ctx context.Context, id string) (*Administrator, error)
 	Insert(ctx context.Context, administrator *Administrator) error
 }
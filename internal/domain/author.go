@@ -0,0 +1,25 @@
+package domain
+
+type Author struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name"`
+}
+
+type PostAuthorDTO struct {
+	AdministratorID string `json:"administrator_id"`
+	DisplayName     string `json:"display_name"`
+}
+
+type SetPostAuthorsDTO struct {
+	Authors []PostAuthorDTO `json:"authors"`
+}
+
+type PostAuthorsResponseDTO struct {
+	PostID  string   `json:"post_id"`
+	Authors []Author `json:"authors"`
+}
+
+type AuthorPostListResponseDTO struct {
+	Author Author `json:"author"`
+	PostListResponseDTO
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Autosave is a snapshot of an editor's unsaved changes to a post. Autosaves
+// are kept apart from post versions until the editor promotes one.
+type Autosave struct {
+	ID            string    `json:"id"`
+	PostID        string    `json:"post_id"`
+	Title         string    `json:"title"`
+	Content       string    `json:"content"`
+	ContentFormat string    `json:"content_format"`
+	Slug          string    `json:"slug"`
+	Excerpt       *string   `json:"excerpt"`
+	SEO           *PostSEO  `json:"seo"`
+	Tags          []string  `json:"tags"`
+	SavedAt       time.Time `json:"saved_at"`
+}
+
+type AutosaveListResponseDTO struct {
+	Autosaves []Autosave `json:"autosaves"`
+}
+
+type PromoteAutosaveDTO struct {
+	EditorEmail string
+	IfMatch     string
+}
+
+type AutosaveRepository interface {
+	// Push stores a snapshot for the administrator and keeps only the newest
+	// limit snapshots.
+	Push(ctx context.Context, administratorID string, autosave *Autosave, limit int, ttl time.Duration) error
+	// List returns the snapshots of the administrator, newest first.
+	List(ctx context.Context, postID, administratorID string) ([]Autosave, error)
+	Delete(ctx context.Context, postID, administratorID string) error
+}
+
+type AutosaveUsecase interface {
+	Save(ctx context.Context, postID string, request *UpdatePostDTO) (*Autosave, error)
+	List(ctx context.Context, postID, editorEmail string) (*AutosaveListResponseDTO, error)
+	Promote(ctx context.Context, postID, autosaveID string, request *PromoteAutosaveDTO) (*PostResponseDTO, error)
+	Discard(ctx context.Context, postID, editorEmail string) error
+}
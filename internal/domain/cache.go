@@ -7,6 +7,7 @@ import (
 
 type CacheRepository interface {
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	// Get returns nil when the key is not cached.
 	Get(ctx context.Context, key string) (interface{}, error)
 	Delete(ctx context.Context, key string) error
 	Clear(ctx context.Context) error
@@ -8,6 +8,7 @@ import (
 type Category struct {
 	ID        string    `json:"id"`
 	Name      string    `json:"name"`
+	ParentID  *string   `json:"parent_id,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -20,15 +21,53 @@ type PostVersionCategory struct {
 
 type CategoryRequestDTO struct {
 	Name string `json:"name"`
+	// ParentID nests the category under another one. On update, nil keeps the
+	// current parent and an empty string moves the category to the top level.
+	ParentID *string `json:"parent_id"`
 }
 
 type CategoryResponseDTO struct {
 	ID        string    `json:"id"`
 	Name      string    `json:"name"`
+	ParentID  *string   `json:"parent_id,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+const (
+	CategorySortName  = "name"
+	CategorySortCount = "count"
+)
+
+type CategoryWithPostCount struct {
+	Category
+	PostCount int `json:"post_count"`
+}
+
+// CategoryTreeNode is a category with its subcategories. PostCount only
+// counts the posts carrying the category itself.
+type CategoryTreeNode struct {
+	CategoryWithPostCount
+	Children []*CategoryTreeNode `json:"children"`
+}
+
+type CategoryTreeResponseDTO struct {
+	Categories []*CategoryTreeNode `json:"categories"`
+}
+
+type CategoryListRequestDTO struct {
+	Sort string
+}
+
+type CategoryListResponseDTO struct {
+	Categories []CategoryWithPostCount `json:"categories"`
+}
+
+type CategoryPostListResponseDTO struct {
+	Category Category `json:"category"`
+	PostListResponseDTO
+}
+
 type AttachCategoryToPostVersionRequestDTO struct {
 	PostVersionID string   `json:"post_version_id"`
 	CategoryIDs   []string `json:"category_ids"`
@@ -37,14 +76,48 @@ type AttachCategoryToPostVersionRequestDTO struct {
 type CategoryRepository interface {
 	Create(ctx context.Context, tx Transaction, category *Category) error
 	Update(ctx context.Context, tx Transaction, category *Category) error
+	// Delete removes a category from every post version and moves its
+	// subcategories up to its own parent.
+	Delete(ctx context.Context, tx Transaction, category *Category, now time.Time) error
 	GetByIDForUpdate(ctx context.Context, tx Transaction, id string) (*Category, error)
+	// LockTree serializes changes to the hierarchy until the transaction ends,
+	// so concurrent moves cannot form a cycle.
+	LockTree(ctx context.Context, tx Transaction) error
+	// GetAncestorIDs returns the ID of a category followed by those of its
+	// ancestors, nearest first.
+	GetAncestorIDs(ctx context.Context, tx Transaction, id string) ([]string, error)
+	GetByID(ctx context.Context, id string) (*Category, error)
 	GetByName(ctx context.Context, name string) (*Category, error)
 	AttachToPostVersion(ctx context.Context, tx Transaction, postVersionCategories []PostVersionCategory) error
+	CopyToPostVersion(ctx context.Context, tx Transaction, fromPostVersionID, toPostVersionID string) error
+	DetachAllFromPostVersion(ctx context.Context, tx Transaction, postVersionID string) error
+	DetachFromPostVersion(ctx context.Context, tx Transaction, postVersionID, categoryID string) error
 	GetByIDs(ctx context.Context, ids []string) ([]*Category, error)
+	GetByPostVersionID(ctx context.Context, postVersionID string) ([]*Category, error)
+	// ListWithPostCounts returns every category with how many listed posts
+	// carry it, ordered by name or by count.
+	ListWithPostCounts(ctx context.Context, sort string) ([]CategoryWithPostCount, error)
+	GetWithPostCount(ctx context.Context, id string) (*CategoryWithPostCount, error)
+}
+
+type ReplacePostVersionCategoriesRequestDTO struct {
+	PostVersionID string   `json:"post_version_id"`
+	CategoryIDs   []string `json:"category_ids"`
+}
+
+type DetachCategoryFromPostVersionRequestDTO struct {
+	PostVersionID string `json:"post_version_id"`
+	CategoryID    string `json:"category_id"`
 }
 
 type CategoryUsecase interface {
+	List(ctx context.Context, request *CategoryListRequestDTO) (*CategoryListResponseDTO, error)
+	GetByID(ctx context.Context, id string) (*CategoryWithPostCount, error)
+	GetTree(ctx context.Context) (*CategoryTreeResponseDTO, error)
 	Create(ctx context.Context, request *CategoryRequestDTO) (*CategoryResponseDTO, error)
 	Update(ctx context.Context, id string, request *CategoryRequestDTO) (*CategoryResponseDTO, error)
+	Delete(ctx context.Context, id string) error
 	AttachToPostVersion(ctx context.Context, request *AttachCategoryToPostVersionRequestDTO) error
+	ReplaceOnPostVersion(ctx context.Context, request *ReplacePostVersionCategoriesRequestDTO) error
+	DetachFromPostVersion(ctx context.Context, request *DetachCategoryFromPostVersionRequestDTO) error
 }
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type EditLease struct {
+	PostID     string    `json:"post_id"`
+	HolderID   string    `json:"holder_id"`
+	HolderName string    `json:"holder_name"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+type EditLeaseRequestDTO struct {
+	EditorEmail string
+	Force       bool
+}
+
+type EditLeaseResponseDTO struct {
+	Acquired bool       `json:"acquired"`
+	Lease    *EditLease `json:"lease"`
+}
+
+// EditLeaseHeldError is returned when another administrator holds the edit
+// lease of a post.
+type EditLeaseHeldError struct {
+	Lease *EditLease
+}
+
+func (e *EditLeaseHeldError) Error() string {
+	return "post is being edited by " + e.Lease.HolderName
+}
+
+type EditLeaseRepository interface {
+	// Acquire takes the lease for lease.HolderID, or extends it when the holder
+	// already has it. It returns false when another holder has the lease.
+	Acquire(ctx context.Context, lease *EditLease, ttl time.Duration) (bool, error)
+	Renew(ctx context.Context, postID, holderID string, ttl time.Duration) (bool, error)
+	Release(ctx context.Context, postID, holderID string) (bool, error)
+	Break(ctx context.Context, postID string) error
+	Get(ctx context.Context, postID string) (*EditLease, error)
+}
+
+type EditLeaseUsecase interface {
+	Acquire(ctx context.Context, postID string, request *EditLeaseRequestDTO) (*EditLeaseResponseDTO, error)
+	Renew(ctx context.Context, postID string, request *EditLeaseRequestDTO) (*EditLeaseResponseDTO, error)
+	Release(ctx context.Context, postID string, request *EditLeaseRequestDTO) error
+	Get(ctx context.Context, postID string) (*EditLease, error)
+}
@@ -5,14 +5,47 @@ import (
 	"time"
 )
 
+const (
+	ContentFormatHTML     = "html"
+	ContentFormatMarkdown = "markdown"
+)
+
 type CreatePostDTO struct {
-	Title   string `json:"title"`
-	Content string `json:"content"`
+	Title         string   `json:"title"`
+	Content       string   `json:"content"`
+	ContentFormat string   `json:"content_format"`
+	Slug          string   `json:"slug"`
+	Excerpt       *string  `json:"excerpt"`
+	Tags          []string `json:"tags"`
+	EditorEmail   string   `json:"-"`
 }
 
 type UpdatePostDTO struct {
-	Title   string `json:"title"`
-	Content string `json:"content"`
+	Title         string   `json:"title"`
+	Content       string   `json:"content"`
+	ContentFormat string   `json:"content_format"`
+	Slug          string   `json:"slug"`
+	Excerpt       *string  `json:"excerpt"`
+	SEO           *PostSEO `json:"seo"`
+	// Tags replace the tags of the draft, or are carried over when nil
+	Tags        []string `json:"tags"`
+	EditorEmail string   `json:"-"`
+	IfMatch     string   `json:"-"`
+}
+
+type PublishPostDTO struct {
+	IfMatch string
+}
+
+type RevertPostDTO struct {
+	VersionNumber int64  `json:"version_number"`
+	Publish       bool   `json:"publish"`
+	EditorEmail   string `json:"-"`
+	IfMatch       string `json:"-"`
+}
+
+type SchedulePostDTO struct {
+	PublishAt time.Time `json:"publish_at"`
 }
 
 type PostDTO struct {
@@ -23,48 +56,207 @@ type PostDTO struct {
 }
 
 type Post struct {
-	ID               string    `json:"id"`
-	CurrentVersionID string    `json:"current_version_id"`
-	CreatedAt        time.Time `json:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at"`
+	ID               string     `json:"id"`
+	CurrentVersionID string     `json:"current_version_id"`
+	Slug             string     `json:"slug"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	UnpublishedAt    *time.Time `json:"unpublished_at"`
+	ArchivedAt       *time.Time `json:"archived_at"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"`
 }
 type PostDetail struct {
 	Post
-	Title         string     `json:"title"`
-	Content       string     `json:"content"`
-	VersionNumber int64      `json:"version_number"`
-	Categories    []Category `json:"categories"`
+	Archived           bool       `json:"archived"`
+	Title              string     `json:"title"`
+	Content            string     `json:"content"`
+	ContentFormat      string     `json:"content_format"`
+	Source             string     `json:"source"`
+	Excerpt            string     `json:"excerpt"`
+	WordCount          int        `json:"word_count"`
+	ReadingTimeMinutes int        `json:"reading_time_minutes"`
+	SEO                PostSEO    `json:"seo"`
+	VersionID          string     `json:"version_id"`
+	VersionNumber      int64      `json:"version_number"`
+	PublishedAt        *time.Time `json:"published_at"`
+	Authors            []Author   `json:"authors"`
+	Categories         []Category `json:"categories"`
+	Tags               []Tag      `json:"tags"`
+}
+
+type BlogPosting struct {
+	Context          string     `json:"@context"`
+	Type             string     `json:"@type"`
+	Headline         string     `json:"headline"`
+	Description      string     `json:"description,omitempty"`
+	Image            string     `json:"image,omitempty"`
+	URL              string     `json:"url,omitempty"`
+	MainEntityOfPage string     `json:"mainEntityOfPage,omitempty"`
+	DatePublished    *time.Time `json:"datePublished,omitempty"`
+	DateModified     time.Time  `json:"dateModified"`
+	WordCount        int        `json:"wordCount"`
+	Keywords         []string   `json:"keywords,omitempty"`
+	Author           []Person   `json:"author,omitempty"`
+}
+
+type Person struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
 }
 
 type PostDetailDTO struct {
 	PostDetail
+	JSONLD *BlogPosting `json:"json_ld,omitempty"`
+	ETag   string       `json:"-"`
+}
+
+// StaleVersionError is returned when an edit was based on an outdated read of
+// a post. It carries the current state so the client can reconcile and retry.
+type StaleVersionError struct {
+	Current *PostDetailDTO
+}
+
+func (e *StaleVersionError) Error() string {
+	return "post was modified since it was read"
+}
+
+type MergeConflictDTO struct {
+	Field     string `json:"field"`
+	BaseStart int    `json:"base_start,omitempty"`
+	Base      string `json:"base"`
+	Theirs    string `json:"theirs"`
+	Ours      string `json:"ours"`
+}
+
+// MergeConflictError is returned when a stale edit could not be merged into
+// the current draft. Theirs is the current draft and ours the rejected edit.
+type MergeConflictError struct {
+	Current   *PostDetailDTO
+	Conflicts []MergeConflictDTO
+}
+
+func (e *MergeConflictError) Error() string {
+	return "edit conflicts with changes made since it was read"
+}
+
+type PostListFilter struct {
+	BeforeID           string
+	CategoryID         string
+	IncludeDescendants bool
+	AuthorID           string
+	TagID              string
+	PublishedAfter     *time.Time
+	PublishedBefore    *time.Time
+	Limit              int
+}
+
+type PostListRequestDTO struct {
+	Cursor             string
+	CategoryID         string
+	IncludeDescendants bool
+	AuthorID           string
+	TagID              string
+	PublishedAfter     *time.Time
+	PublishedBefore    *time.Time
+	Limit              int
+}
+
+type PostListResponseDTO struct {
+	Posts      []PostDetail `json:"posts"`
+	NextCursor string       `json:"next_cursor,omitempty"`
 }
 
 type PostRepository interface {
 	GetByID(ctx context.Context, id string) (*PostDetail, error)
+	GetDraftByID(ctx context.Context, id string) (*PostDetail, error)
+	GetBySlug(ctx context.Context, slug string) (*PostDetail, error)
+	List(ctx context.Context, filter *PostListFilter) ([]*PostDetail, error)
 	Create(ctx context.Context, tx Transaction, post *Post) error
 	Update(ctx context.Context, tx Transaction, post *Post) error
 	GetByIDForUpdate(ctx context.Context, tx Transaction, id string) (*Post, error)
+	GetDeletedByIDForUpdate(ctx context.Context, tx Transaction, id string) (*Post, error)
+	ListDeleted(ctx context.Context, filter *PostListFilter) ([]*PostDetail, error)
+	PurgeDeleted(ctx context.Context, tx Transaction, before time.Time) (int64, error)
+	ReplaceAuthors(ctx context.Context, tx Transaction, postID string, authors []PostAuthorDTO) error
 }
 
 type PostUsecase interface {
 	GetByID(ctx context.Context, id string) (*PostDetailDTO, error)
+	GetDraftByID(ctx context.Context, id string) (*PostDetailDTO, error)
+	GetBySlug(ctx context.Context, slug string) (*PostDetailDTO, string, error)
+	List(ctx context.Context, request *PostListRequestDTO) (*PostListResponseDTO, error)
+	ListVersions(ctx context.Context, id string) (*PostVersionListResponseDTO, error)
+	GetVersion(ctx context.Context, id string, versionNumber int64) (*PostVersionDetailDTO, error)
+	DiffVersions(ctx context.Context, id string, request *PostVersionDiffRequestDTO) (*PostVersionDiffDTO, error)
 	Create(ctx context.Context, post *CreatePostDTO) (*PostResponseDTO, error)
 	Update(ctx context.Context, id string, post *UpdatePostDTO) (*PostResponseDTO, error)
-	Publish(ctx context.Context, id string) (*PublishResponseDTO, error)
+	Publish(ctx context.Context, id string, request *PublishPostDTO) (*PublishResponseDTO, error)
+	Revert(ctx context.Context, id string, request *RevertPostDTO) (*RevertResponseDTO, error)
+	Schedule(ctx context.Context, id string, request *SchedulePostDTO) (*ScheduleResponseDTO, error)
+	CancelSchedule(ctx context.Context, id string) (*ScheduleResponseDTO, error)
+	PublishScheduled(ctx context.Context) (int, error)
+	ReindexSearch(ctx context.Context) (int, error)
+	Unpublish(ctx context.Context, id string) (*PostStatusResponseDTO, error)
+	Archive(ctx context.Context, id string) (*PostStatusResponseDTO, error)
+	Unarchive(ctx context.Context, id string) (*PostStatusResponseDTO, error)
+	Delete(ctx context.Context, id string) (*PostStatusResponseDTO, error)
+	Restore(ctx context.Context, id string) (*PostStatusResponseDTO, error)
+	ListDeleted(ctx context.Context, request *PostListRequestDTO) (*PostListResponseDTO, error)
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	SetAuthors(ctx context.Context, id string, request *SetPostAuthorsDTO) (*PostAuthorsResponseDTO, error)
+	ListByAuthor(ctx context.Context, authorID string, request *PostListRequestDTO) (*AuthorPostListResponseDTO, error)
+	ListByTag(ctx context.Context, tagSlug string, request *PostListRequestDTO) (*TagPostListResponseDTO, error)
+	ListByCategory(ctx context.Context, categoryID string, request *PostListRequestDTO) (*CategoryPostListResponseDTO, error)
 	DeletePostVersionByPostID(ctx context.Context, id string) error
 }
 
 type PostResponseDTO struct {
-	PostID        string `json:"post_id"`
-	PostVersionID string `json:"post_version_id"`
-	Title         string `json:"title"`
-	Content       string `json:"content"`
+	PostID        string  `json:"post_id"`
+	PostVersionID string  `json:"post_version_id"`
+	Slug          string  `json:"slug"`
+	Title         string  `json:"title"`
+	Content       string  `json:"content"`
+	ContentFormat string  `json:"content_format"`
+	Source        string  `json:"source"`
+	Excerpt       string  `json:"excerpt"`
+	SEO           PostSEO `json:"seo"`
+	Tags          []Tag   `json:"tags"`
+	Merged        bool    `json:"merged"`
+	ETag          string  `json:"-"`
 }
 
 type PublishResponseDTO struct {
 	PostID      string     `json:"post_id"`
+	Slug        string     `json:"slug"`
 	PublishedAt *time.Time `json:"published_at"`
 	Title       string     `json:"title"`
 	Content     string     `json:"content"`
+	ETag        string     `json:"-"`
+}
+
+type RevertResponseDTO struct {
+	PostID        string     `json:"post_id"`
+	PostVersionID string     `json:"post_version_id"`
+	VersionNumber int64      `json:"version_number"`
+	RevertedFrom  int64      `json:"reverted_from"`
+	Slug          string     `json:"slug"`
+	Title         string     `json:"title"`
+	Content       string     `json:"content"`
+	PublishedAt   *time.Time `json:"published_at"`
+	ETag          string     `json:"-"`
+}
+
+type ScheduleResponseDTO struct {
+	PostID        string     `json:"post_id"`
+	PostVersionID string     `json:"post_version_id"`
+	VersionNumber int64      `json:"version_number"`
+	PublishAt     *time.Time `json:"publish_at"`
+}
+
+type PostStatusResponseDTO struct {
+	PostID        string     `json:"post_id"`
+	Slug          string     `json:"slug"`
+	UnpublishedAt *time.Time `json:"unpublished_at"`
+	ArchivedAt    *time.Time `json:"archived_at"`
+	DeletedAt     *time.Time `json:"deleted_at"`
 }
@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// PostSlug is a slug a post was previously published under.
+type PostSlug struct {
+	Slug      string    `json:"slug"`
+	PostID    string    `json:"post_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PostSlugRepository interface {
+	Create(ctx context.Context, tx Transaction, postSlug *PostSlug) error
+	Delete(ctx context.Context, tx Transaction, postID, slug string) error
+	GetCurrentSlug(ctx context.Context, slug string) (string, error)
+	IsTaken(ctx context.Context, tx Transaction, slug, postID string) (bool, error)
+}
@@ -6,19 +6,133 @@ import (
 )
 
 type PostVersion struct {
+	ID                 string     `json:"id"`
+	VersionNumber      int64      `json:"version_number"`
+	PostID             string     `json:"post_id"`
+	Title              string     `json:"title"`
+	Slug               string     `json:"slug"`
+	Content            string     `json:"content"`
+	ContentFormat      string     `json:"content_format"`
+	Source             string     `json:"source"`
+	Excerpt            string     `json:"excerpt"`
+	CustomExcerpt      bool       `json:"custom_excerpt"`
+	WordCount          int        `json:"word_count"`
+	ReadingTimeMinutes int        `json:"reading_time_minutes"`
+	SEO                PostSEO    `json:"seo"`
+	EditorID           *string    `json:"editor_id"`
+	PublishedAt        *time.Time `json:"published_at"`
+	PublishAt          *time.Time `json:"publish_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+type PostSEO struct {
+	MetaDescription string `json:"meta_description"`
+	CanonicalURL    string `json:"canonical_url"`
+	OGImage         string `json:"og_image"`
+	OGTitle         string `json:"og_title"`
+	OGDescription   string `json:"og_description"`
+	NoIndex         bool   `json:"noindex"`
+}
+
+type PostVersionSummaryDTO struct {
 	ID            string     `json:"id"`
 	VersionNumber int64      `json:"version_number"`
-	PostID        string     `json:"post_id"`
 	Title         string     `json:"title"`
-	Content       string     `json:"content"`
 	PublishedAt   *time.Time `json:"published_at"`
+	PublishAt     *time.Time `json:"publish_at"`
+	EditorID      *string    `json:"editor_id"`
 	CreatedAt     time.Time  `json:"created_at"`
 }
 
+type PostVersionListResponseDTO struct {
+	Versions []PostVersionSummaryDTO `json:"versions"`
+}
+
+type PostVersionDetailDTO struct {
+	PostVersion
+	Categories []Category `json:"categories"`
+	Tags       []Tag      `json:"tags"`
+}
+
+const (
+	DiffGranularityLine = "line"
+	DiffGranularityWord = "word"
+	DiffFormatJSON      = "json"
+	DiffFormatUnified   = "unified"
+)
+
+type PostVersionDiffRequestDTO struct {
+	FromVersion int64
+	ToVersion   int64
+	Granularity string
+	Format      string
+}
+
+type DiffChangeDTO struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type DiffHunkDTO struct {
+	FromStart int             `json:"from_start"`
+	FromCount int             `json:"from_count"`
+	ToStart   int             `json:"to_start"`
+	ToCount   int             `json:"to_count"`
+	Changes   []DiffChangeDTO `json:"changes"`
+}
+
+type CategoryDiffDTO struct {
+	Added   []Category `json:"added"`
+	Removed []Category `json:"removed"`
+}
+
+// FieldChangeDTO is a metadata field that differs between two versions.
+type FieldChangeDTO struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// PostVersionDiffDTO compares two versions. Content is diffed on the source the
+// author wrote rather than the rendered HTML.
+type PostVersionDiffDTO struct {
+	PostID      string           `json:"post_id"`
+	FromVersion int64            `json:"from_version"`
+	ToVersion   int64            `json:"to_version"`
+	Granularity string           `json:"granularity"`
+	Title       []DiffHunkDTO    `json:"title"`
+	Content     []DiffHunkDTO    `json:"content"`
+	Metadata    []FieldChangeDTO `json:"metadata"`
+	Categories  CategoryDiffDTO  `json:"categories"`
+	Unified     string           `json:"unified,omitempty"`
+}
+
+// PostVersionRevision is the content a draft had before it was edited in
+// place, kept under its entity tag so stale edits made from it can still be
+// merged.
+type PostVersionRevision struct {
+	PostVersionID string
+	ETag          string
+	Title         string
+	Slug          string
+	ContentFormat string
+	Source        string
+	CreatedAt     time.Time
+}
+
 type PostVersionRepository interface {
 	Create(ctx context.Context, tx Transaction, postVersion *PostVersion) error
 	Update(ctx context.Context, tx Transaction, postVersion *PostVersion) error
 	GetLatestByPostIDForUpdate(ctx context.Context, tx Transaction, postID string) (*PostVersion, error)
 	Delete(ctx context.Context, tx Transaction, id string) error
 	GetByID(ctx context.Context, id string) (*PostVersion, error)
+	GetByIDForUpdate(ctx context.Context, tx Transaction, id string) (*PostVersion, error)
+	GetByPostIDAndVersionNumber(ctx context.Context, postID string, versionNumber int64) (*PostVersion, error)
+	GetByPostIDAndVersionNumberForUpdate(ctx context.Context, tx Transaction, postID string, versionNumber int64) (*PostVersion, error)
+	ListByPostID(ctx context.Context, postID string) ([]*PostVersion, error)
+	GetNextDue(ctx context.Context, now time.Time, excludeIDs []string) (*PostVersion, error)
+	// SaveRevision stores a revision and drops all but the latest keep
+	// revisions of its version.
+	SaveRevision(ctx context.Context, tx Transaction, revision *PostVersionRevision, keep int) error
+	GetRevision(ctx context.Context, postVersionID, etag string) (*PostVersionRevision, error)
 }
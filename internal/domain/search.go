@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// SearchDocument is the full-text index entry of the current version of a post.
+type SearchDocument struct {
+	PostID        string
+	PostVersionID string
+	Language      string
+	Title         string
+	Body          string
+	UpdatedAt     time.Time
+}
+
+type SearchFilter struct {
+	Query      string
+	Language   string
+	CategoryID string
+	Offset     int
+	Limit      int
+}
+
+type SearchResult struct {
+	PostID      string     `json:"post_id"`
+	Slug        string     `json:"slug"`
+	Title       string     `json:"title"`
+	Snippet     string     `json:"snippet"`
+	Rank        float64    `json:"rank"`
+	PublishedAt *time.Time `json:"published_at"`
+}
+
+type SearchRequestDTO struct {
+	Query      string
+	CategoryID string
+	Cursor     string
+	Limit      int
+}
+
+type SearchResponseDTO struct {
+	Results    []SearchResult `json:"results"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+const (
+	SuggestionTypePost     = "post"
+	SuggestionTypeCategory = "category"
+)
+
+// Suggestion is a published post title or category name resembling what is
+// being typed in the search box.
+type Suggestion struct {
+	Type  string  `json:"type"`
+	ID    string  `json:"id"`
+	Slug  string  `json:"slug,omitempty"`
+	Text  string  `json:"text"`
+	Score float64 `json:"score"`
+}
+
+type SuggestRequestDTO struct {
+	Query string
+	Limit int
+}
+
+type SuggestResponseDTO struct {
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+type SearchRepository interface {
+	// Index replaces the search document of a post.
+	Index(ctx context.Context, tx Transaction, document *SearchDocument) error
+	// Search ranks published posts matching the query. Snippets mark matches
+	// with SnippetStartSel and SnippetStopSel.
+	Search(ctx context.Context, filter *SearchFilter) ([]SearchResult, error)
+	// Suggest ranks titles and category names by trigram similarity to the
+	// lowercased query, boosting those that start with it.
+	Suggest(ctx context.Context, query string, limit int) ([]Suggestion, error)
+	// ListStale returns the current version IDs of searchable posts whose
+	// document is missing or was built with another language.
+	ListStale(ctx context.Context, language string) ([]string, error)
+}
+
+// Snippet match markers, from the Unicode private use area so they never occur
+// in indexed text.
+const (
+	SnippetStartSel = "\ue000"
+	SnippetStopSel  = "\ue001"
+)
+
+type SearchUsecase interface {
+	Search(ctx context.Context, request *SearchRequestDTO) (*SearchResponseDTO, error)
+	Suggest(ctx context.Context, request *SuggestRequestDTO) (*SuggestResponseDTO, error)
+}
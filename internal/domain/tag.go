@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Tag is a free-form label authors attach to posts by name. Unlike categories,
+// tags are created on the fly and identified by their slug.
+type Tag struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+type TagWithPostCount struct {
+	Tag
+	PostCount int `json:"post_count"`
+}
+
+type TagListResponseDTO struct {
+	Tags []TagWithPostCount `json:"tags"`
+}
+
+type TagPostListResponseDTO struct {
+	Tag Tag `json:"tag"`
+	PostListResponseDTO
+}
+
+type TagRepository interface {
+	// Upsert creates the tags that do not exist yet, matched by slug, and
+	// returns all of them with their IDs.
+	Upsert(ctx context.Context, tx Transaction, tags []Tag, now time.Time) ([]Tag, error)
+	// ReplaceOnPostVersion sets the tags of a post version to tagIDs.
+	ReplaceOnPostVersion(ctx context.Context, tx Transaction, postVersionID string, tagIDs []string) error
+	// CopyToPostVersion replaces the tags of a post version with those of another.
+	CopyToPostVersion(ctx context.Context, tx Transaction, fromPostVersionID, toPostVersionID string) error
+	GetByPostVersionID(ctx context.Context, postVersionID string) ([]Tag, error)
+	GetBySlug(ctx context.Context, slug string) (*Tag, error)
+	// ListWithPostCounts returns the tags of listed posts with how many listed
+	// posts carry them, most used first.
+	ListWithPostCounts(ctx context.Context) ([]TagWithPostCount, error)
+}
+
+type TagUsecase interface {
+	List(ctx context.Context) (*TagListResponseDTO, error)
+}
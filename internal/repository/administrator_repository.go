@@ -51,6 +51,23 @@ func (r *AdministratorRepositoryImpl) FindByEmail(ctx context.Context, email str
 	return &admin, nil
 }
 
+func (r *AdministratorRepositoryImpl) FindByID(ctx context.Context, id string) (*domain.Administrator, error) {
+	query := `SELECT id, full_name, email, password_hash, created_at, updated_at FROM administrators WHERE id = $1 AND deleted_at IS NULL`
+	row := r.db.QueryRowContext(ctx, query, id)
+
+	admin := domain.Administrator{}
+	err := row.Scan(&admin.ID, &admin.FullName, &admin.Email, &admin.PasswordHash, &admin.CreatedAt, &admin.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, common.ErrUserNotFound
+		}
+		logger.Log.Error("failed to get administrator by id", zap.Error(err), zap.String("id", id))
+		return nil, err
+	}
+
+	return &admin, nil
+}
+
 func (r *AdministratorRepositoryImpl) Insert(ctx context.Context, administrator *domain.Administrator) error {
 	if administrator == nil {
 		return common.NewCustomError(http.StatusBadRequest, "administrator data is nil")
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"livoir-blog/internal/domain"
+	"livoir-blog/pkg/common"
+	"livoir-blog/pkg/logger"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const autosaveKeyPrefix = "post_autosave:"
+
+type AutosaveRepositoryRedis struct {
+	Client *redis.Client
+}
+
+func NewAutosaveRepositoryRedis(client *redis.Client) (domain.AutosaveRepository, error) {
+	if client == nil {
+		logger.Log.Error("Redis client is nil")
+		return nil, common.ErrInternalServerError
+	}
+	return &AutosaveRepositoryRedis{Client: client}, nil
+}
+
+func autosaveKey(postID, administratorID string) string {
+	return autosaveKeyPrefix + postID + ":" + administratorID
+}
+
+func (r *AutosaveRepositoryRedis) Push(ctx context.Context, administratorID string, autosave *domain.Autosave, limit int, ttl time.Duration) error {
+	value, err := json.Marshal(autosave)
+	if err != nil {
+		logger.Log.Error("Failed to marshal autosave", zap.Error(err))
+		return common.ErrInternalServerError
+	}
+	key := autosaveKey(autosave.PostID, administratorID)
+	pipe := r.Client.TxPipeline()
+	pipe.LPush(ctx, key, value)
+	pipe.LTrim(ctx, key, 0, int64(limit-1))
+	pipe.PExpire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Log.Error("Failed to push autosave", zap.String("post_id", autosave.PostID), zap.Error(err))
+		return common.ErrInternalServerError
+	}
+	return nil
+}
+
+func (r *AutosaveRepositoryRedis) List(ctx context.Context, postID, administratorID string) ([]domain.Autosave, error) {
+	values, err := r.Client.LRange(ctx, autosaveKey(postID, administratorID), 0, -1).Result()
+	if err != nil {
+		logger.Log.Error("Failed to list autosaves", zap.String("post_id", postID), zap.Error(err))
+		return nil, common.ErrInternalServerError
+	}
+	autosaves := make([]domain.Autosave, 0, len(values))
+	for _, value := range values {
+		var autosave domain.Autosave
+		if err := json.Unmarshal([]byte(value), &autosave); err != nil {
+			logger.Log.Error("Failed to unmarshal autosave", zap.String("post_id", postID), zap.Error(err))
+			return nil, common.ErrInternalServerError
+		}
+		autosaves = append(autosaves, autosave)
+	}
+	return autosaves, nil
+}
+
+func (r *AutosaveRepositoryRedis) Delete(ctx context.Context, postID, administratorID string) error {
+	err := r.Client.Del(ctx, autosaveKey(postID, administratorID)).Err()
+	if err != nil {
+		logger.Log.Error("Failed to delete autosaves", zap.String("post_id", postID), zap.Error(err))
+		return common.ErrInternalServerError
+	}
+	return nil
+}
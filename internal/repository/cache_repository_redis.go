@@ -49,6 +49,9 @@ func (c *CacheRepositoryRedis) Delete(ctx context.Context, key string) error {
 
 func (c *CacheRepositoryRedis) Get(ctx context.Context, key string) (interface{}, error) {
 	result, err := c.Client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
 	if err != nil {
 		logger.Log.Error("Failed to get value from cache: ", zap.String("key", key), zap.Error(err))
 		return nil, common.ErrInternalServerError
@@ -10,6 +10,7 @@ import (
 	"livoir-blog/pkg/ulid"
 	"net/http"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -25,11 +26,34 @@ func NewCategoryRepository(db *sql.DB) (domain.CategoryRepository, error) {
 	return &CategoryRepository{db: db}, nil
 }
 
+// categoryPostCountQuery counts the same posts the post listing shows, so
+// drafts do not show up in the counts.
+const categoryPostCountQuery = `SELECT c.id, c.name, c.parent_id, c.created_at, c.updated_at, COUNT(p.id) FROM categories c LEFT JOIN post_version_categories pvc ON c.id = pvc.category_id LEFT JOIN post_versions pv ON pvc.post_version_id = pv.id AND pv.published_at IS NOT NULL LEFT JOIN posts p ON p.current_version_id = pv.id AND p.unpublished_at IS NULL AND p.archived_at IS NULL AND p.deleted_at IS NULL`
+
+var categorySortOrders = map[string]string{
+	domain.CategorySortName:  "c.name, c.id",
+	domain.CategorySortCount: "COUNT(p.id) DESC, c.name, c.id",
+}
+
+func (r *CategoryRepository) GetByID(ctx context.Context, id string) (*domain.Category, error) {
+	query := `SELECT id, name, parent_id, created_at, updated_at FROM categories WHERE id = $1`
+	var category domain.Category
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&category.ID, &category.Name, &category.ParentID, &category.CreatedAt, &category.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, common.ErrCategoryNotFound
+		}
+		logger.Log.Error("Failed to get category by id", zap.Error(err))
+		return nil, common.ErrInternalServerError
+	}
+	return &category, nil
+}
+
 func (r *CategoryRepository) GetByName(ctx context.Context, name string) (*domain.Category, error) {
-	query := `SELECT id, name, created_at, updated_at FROM categories WHERE name = $1`
+	query := `SELECT id, name, parent_id, created_at, updated_at FROM categories WHERE name = $1`
 	row := r.db.QueryRowContext(ctx, query, name)
 	var category domain.Category
-	err := row.Scan(&category.ID, &category.Name, &category.CreatedAt, &category.UpdatedAt)
+	err := row.Scan(&category.ID, &category.Name, &category.ParentID, &category.CreatedAt, &category.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, common.ErrCategoryNotFound
@@ -43,8 +67,8 @@ func (r *CategoryRepository) GetByName(ctx context.Context, name string) (*domai
 func (r *CategoryRepository) Create(ctx context.Context, tx domain.Transaction, category *domain.Category) error {
 	sqlTx := tx.GetTx()
 	category.ID = ulid.New()
-	query := `INSERT INTO categories (id, name, created_at, updated_at) VALUES ($1, $2, $3, $4)`
-	result, err := sqlTx.ExecContext(ctx, query, category.ID, category.Name, category.CreatedAt, category.UpdatedAt)
+	query := `INSERT INTO categories (id, name, parent_id, created_at, updated_at) VALUES ($1, $2, $3, $4, $5)`
+	result, err := sqlTx.ExecContext(ctx, query, category.ID, category.Name, category.ParentID, category.CreatedAt, category.UpdatedAt)
 	if err != nil {
 		logger.Log.Error("Failed to create category", zap.Error(err))
 		return common.ErrInternalServerError
@@ -62,8 +86,8 @@ func (r *CategoryRepository) Create(ctx context.Context, tx domain.Transaction,
 
 func (r *CategoryRepository) Update(ctx context.Context, tx domain.Transaction, category *domain.Category) error {
 	sqlTx := tx.GetTx()
-	query := `UPDATE categories SET name = $1, updated_at = $2 WHERE id = $3`
-	result, err := sqlTx.ExecContext(ctx, query, category.Name, category.UpdatedAt, category.ID)
+	query := `UPDATE categories SET name = $1, parent_id = $2, updated_at = $3 WHERE id = $4`
+	result, err := sqlTx.ExecContext(ctx, query, category.Name, category.ParentID, category.UpdatedAt, category.ID)
 	if err != nil {
 		logger.Log.Error("Failed to update category", zap.Error(err))
 		return common.ErrInternalServerError
@@ -79,12 +103,78 @@ func (r *CategoryRepository) Update(ctx context.Context, tx domain.Transaction,
 	return nil
 }
 
+func (r *CategoryRepository) Delete(ctx context.Context, tx domain.Transaction, category *domain.Category, now time.Time) error {
+	sqlTx := tx.GetTx()
+	_, err := sqlTx.ExecContext(ctx, "UPDATE categories SET parent_id = $1, updated_at = $2 WHERE parent_id = $3", category.ParentID, now, category.ID)
+	if err != nil {
+		logger.Log.Error("Failed to move subcategories", zap.Error(err))
+		return common.ErrInternalServerError
+	}
+	_, err = sqlTx.ExecContext(ctx, "DELETE FROM post_version_categories WHERE category_id = $1", category.ID)
+	if err != nil {
+		logger.Log.Error("Failed to detach category from post versions", zap.Error(err))
+		return common.ErrInternalServerError
+	}
+	result, err := sqlTx.ExecContext(ctx, "DELETE FROM categories WHERE id = $1", category.ID)
+	if err != nil {
+		logger.Log.Error("Failed to delete category", zap.Error(err))
+		return common.ErrInternalServerError
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Log.Error("Failed to get rows affected", zap.Error(err))
+		return common.ErrInternalServerError
+	}
+	if rowsAffected == 0 {
+		return common.ErrCategoryNotFound
+	}
+	return nil
+}
+
+func (r *CategoryRepository) LockTree(ctx context.Context, tx domain.Transaction) error {
+	sqlTx := tx.GetTx()
+	_, err := sqlTx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext('category_tree'))")
+	if err != nil {
+		logger.Log.Error("Failed to lock category tree", zap.Error(err))
+		return common.ErrInternalServerError
+	}
+	return nil
+}
+
+func (r *CategoryRepository) GetAncestorIDs(ctx context.Context, tx domain.Transaction, id string) ([]string, error) {
+	sqlTx := tx.GetTx()
+	query := `WITH RECURSIVE ancestors AS (SELECT id, parent_id, 0 AS depth FROM categories WHERE id = $1 UNION ALL SELECT c.id, c.parent_id, a.depth + 1 FROM categories c JOIN ancestors a ON c.id = a.parent_id) SELECT id FROM ancestors ORDER BY depth`
+	rows, err := sqlTx.QueryContext(ctx, query, id)
+	if err != nil {
+		logger.Log.Error("Failed to get category ancestors", zap.Error(err))
+		return nil, common.ErrInternalServerError
+	}
+	defer rows.Close()
+	ids := []string{}
+	for rows.Next() {
+		var ancestorID string
+		if err := rows.Scan(&ancestorID); err != nil {
+			logger.Log.Error("Failed to scan category ancestor", zap.Error(err))
+			return nil, common.ErrInternalServerError
+		}
+		ids = append(ids, ancestorID)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Log.Error("Failed to iterate category ancestors", zap.Error(err))
+		return nil, common.ErrInternalServerError
+	}
+	if len(ids) == 0 {
+		return nil, common.ErrCategoryNotFound
+	}
+	return ids, nil
+}
+
 func (r *CategoryRepository) GetByIDForUpdate(ctx context.Context, tx domain.Transaction, id string) (*domain.Category, error) {
 	sqlTx := tx.GetTx()
-	query := `SELECT id, name, created_at, updated_at FROM categories WHERE id = $1 FOR UPDATE`
+	query := `SELECT id, name, parent_id, created_at, updated_at FROM categories WHERE id = $1 FOR UPDATE`
 	row := sqlTx.QueryRowContext(ctx, query, id)
 	var category domain.Category
-	err := row.Scan(&category.ID, &category.Name, &category.CreatedAt, &category.UpdatedAt)
+	err := row.Scan(&category.ID, &category.Name, &category.ParentID, &category.CreatedAt, &category.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, common.ErrCategoryNotFound
@@ -123,6 +213,45 @@ func (r *CategoryRepository) AttachToPostVersion(ctx context.Context, tx domain.
 	return nil
 }
 
+func (r *CategoryRepository) CopyToPostVersion(ctx context.Context, tx domain.Transaction, fromPostVersionID, toPostVersionID string) error {
+	sqlTx := tx.GetTx()
+	query := `INSERT INTO post_version_categories (post_version_id, category_id) SELECT $2, category_id FROM post_version_categories WHERE post_version_id = $1 ON CONFLICT DO NOTHING`
+	_, err := sqlTx.ExecContext(ctx, query, fromPostVersionID, toPostVersionID)
+	if err != nil {
+		logger.Log.Error("Failed to copy categories to post version", zap.Error(err))
+		return common.ErrInternalServerError
+	}
+	return nil
+}
+
+func (r *CategoryRepository) DetachAllFromPostVersion(ctx context.Context, tx domain.Transaction, postVersionID string) error {
+	sqlTx := tx.GetTx()
+	_, err := sqlTx.ExecContext(ctx, "DELETE FROM post_version_categories WHERE post_version_id = $1", postVersionID)
+	if err != nil {
+		logger.Log.Error("Failed to detach categories from post version", zap.Error(err))
+		return common.ErrInternalServerError
+	}
+	return nil
+}
+
+func (r *CategoryRepository) DetachFromPostVersion(ctx context.Context, tx domain.Transaction, postVersionID, categoryID string) error {
+	sqlTx := tx.GetTx()
+	result, err := sqlTx.ExecContext(ctx, "DELETE FROM post_version_categories WHERE post_version_id = $1 AND category_id = $2", postVersionID, categoryID)
+	if err != nil {
+		logger.Log.Error("Failed to detach category from post version", zap.Error(err))
+		return common.ErrInternalServerError
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Log.Error("Failed to get rows affected", zap.Error(err))
+		return common.ErrInternalServerError
+	}
+	if rowsAffected == 0 {
+		return common.NewCustomError(http.StatusNotFound, "category is not attached to post version")
+	}
+	return nil
+}
+
 func (r *CategoryRepository) GetByIDs(ctx context.Context, ids []string) ([]*domain.Category, error) {
 	valueStrings := make([]string, 0, len(ids))
 	valueArgs := make([]interface{}, 0, len(ids))
@@ -130,7 +259,7 @@ func (r *CategoryRepository) GetByIDs(ctx context.Context, ids []string) ([]*dom
 		valueStrings = append(valueStrings, fmt.Sprintf("($%d)", i+1))
 		valueArgs = append(valueArgs, id)
 	}
-	query := fmt.Sprintf(`SELECT id, name, created_at, updated_at FROM categories WHERE id IN (%s)`, strings.Join(valueStrings, ",")) //#nosec G201
+	query := fmt.Sprintf(`SELECT id, name, parent_id, created_at, updated_at FROM categories WHERE id IN (%s)`, strings.Join(valueStrings, ",")) //#nosec G201
 	rows, err := r.db.QueryContext(ctx, query, valueArgs...)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -143,7 +272,7 @@ func (r *CategoryRepository) GetByIDs(ctx context.Context, ids []string) ([]*dom
 	defer rows.Close()
 	for rows.Next() {
 		var category domain.Category
-		err := rows.Scan(&category.ID, &category.Name, &category.CreatedAt, &category.UpdatedAt)
+		err := rows.Scan(&category.ID, &category.Name, &category.ParentID, &category.CreatedAt, &category.UpdatedAt)
 		if err != nil {
 			logger.Log.Error("Failed to scan category", zap.Error(err))
 			return nil, common.ErrInternalServerError
@@ -152,3 +281,66 @@ func (r *CategoryRepository) GetByIDs(ctx context.Context, ids []string) ([]*dom
 	}
 	return categories, nil
 }
+
+func (r *CategoryRepository) GetByPostVersionID(ctx context.Context, postVersionID string) ([]*domain.Category, error) {
+	query := `SELECT c.id, c.name, c.parent_id, c.created_at, c.updated_at FROM categories c JOIN post_version_categories pvc ON c.id = pvc.category_id WHERE pvc.post_version_id = $1 ORDER BY c.name`
+	rows, err := r.db.QueryContext(ctx, query, postVersionID)
+	if err != nil {
+		logger.Log.Error("Failed to get categories by post version id", zap.Error(err))
+		return nil, common.ErrInternalServerError
+	}
+	defer rows.Close()
+	var categories []*domain.Category
+	for rows.Next() {
+		var category domain.Category
+		err := rows.Scan(&category.ID, &category.Name, &category.ParentID, &category.CreatedAt, &category.UpdatedAt)
+		if err != nil {
+			logger.Log.Error("Failed to scan category", zap.Error(err))
+			return nil, common.ErrInternalServerError
+		}
+		categories = append(categories, &category)
+	}
+	return categories, nil
+}
+
+func (r *CategoryRepository) ListWithPostCounts(ctx context.Context, sort string) ([]domain.CategoryWithPostCount, error) {
+	order, ok := categorySortOrders[sort]
+	if !ok {
+		return nil, common.NewCustomError(http.StatusBadRequest, "sort must be name or count")
+	}
+	query := fmt.Sprintf(`%s GROUP BY c.id, c.name, c.parent_id, c.created_at, c.updated_at ORDER BY %s`, categoryPostCountQuery, order) //#nosec G201
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		logger.Log.Error("Failed to list categories", zap.Error(err))
+		return nil, common.ErrInternalServerError
+	}
+	defer rows.Close()
+	categories := []domain.CategoryWithPostCount{}
+	for rows.Next() {
+		var category domain.CategoryWithPostCount
+		if err := rows.Scan(&category.ID, &category.Name, &category.ParentID, &category.CreatedAt, &category.UpdatedAt, &category.PostCount); err != nil {
+			logger.Log.Error("Failed to scan category", zap.Error(err))
+			return nil, common.ErrInternalServerError
+		}
+		categories = append(categories, category)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Log.Error("Failed to iterate categories", zap.Error(err))
+		return nil, common.ErrInternalServerError
+	}
+	return categories, nil
+}
+
+func (r *CategoryRepository) GetWithPostCount(ctx context.Context, id string) (*domain.CategoryWithPostCount, error) {
+	query := categoryPostCountQuery + ` WHERE c.id = $1 GROUP BY c.id, c.name, c.parent_id, c.created_at, c.updated_at`
+	var category domain.CategoryWithPostCount
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&category.ID, &category.Name, &category.ParentID, &category.CreatedAt, &category.UpdatedAt, &category.PostCount)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, common.ErrCategoryNotFound
+		}
+		logger.Log.Error("Failed to get category with post count", zap.Error(err))
+		return nil, common.ErrInternalServerError
+	}
+	return &category, nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"livoir-blog/internal/domain"
+	"livoir-blog/pkg/common"
+	"livoir-blog/pkg/logger"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const editLeaseKeyPrefix = "post_edit_lease:"
+
+// Leases are hashes so the holder can be compared atomically in Lua
+var (
+	acquireEditLeaseScript = redis.NewScript(`
+local holder = redis.call('HGET', KEYS[1], 'holder_id')
+if holder and holder ~= ARGV[1] then
+	return 0
+end
+if not holder then
+	redis.call('HSET', KEYS[1], 'holder_id', ARGV[1], 'holder_name', ARGV[2], 'acquired_at', ARGV[3])
+end
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+return 1
+`)
+	renewEditLeaseScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'holder_id') ~= ARGV[1] then
+	return 0
+end
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return 1
+`)
+	releaseEditLeaseScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'holder_id') ~= ARGV[1] then
+	return 0
+end
+return redis.call('DEL', KEYS[1])
+`)
+)
+
+type EditLeaseRepositoryRedis struct {
+	Client *redis.Client
+}
+
+func NewEditLeaseRepositoryRedis(client *redis.Client) (domain.EditLeaseRepository, error) {
+	if client == nil {
+		logger.Log.Error("Redis client is nil")
+		return nil, common.ErrInternalServerError
+	}
+	return &EditLeaseRepositoryRedis{Client: client}, nil
+}
+
+func (r *EditLeaseRepositoryRedis) Acquire(ctx context.Context, lease *domain.EditLease, ttl time.Duration) (bool, error) {
+	acquiredAt := strconv.FormatInt(lease.AcquiredAt.UnixMilli(), 10)
+	return r.run(ctx, acquireEditLeaseScript, lease.PostID, lease.HolderID, lease.HolderName, acquiredAt, ttl.Milliseconds())
+}
+
+func (r *EditLeaseRepositoryRedis) Renew(ctx context.Context, postID, holderID string, ttl time.Duration) (bool, error) {
+	return r.run(ctx, renewEditLeaseScript, postID, holderID, ttl.Milliseconds())
+}
+
+func (r *EditLeaseRepositoryRedis) Release(ctx context.Context, postID, holderID string) (bool, error) {
+	return r.run(ctx, releaseEditLeaseScript, postID, holderID)
+}
+
+func (r *EditLeaseRepositoryRedis) run(ctx context.Context, script *redis.Script, postID string, args ...interface{}) (bool, error) {
+	result, err := script.Run(ctx, r.Client, []string{editLeaseKeyPrefix + postID}, args...).Int()
+	if err != nil {
+		logger.Log.Error("Failed to run edit lease script", zap.String("post_id", postID), zap.Error(err))
+		return false, common.ErrInternalServerError
+	}
+	return result == 1, nil
+}
+
+func (r *EditLeaseRepositoryRedis) Break(ctx context.Context, postID string) error {
+	err := r.Client.Del(ctx, editLeaseKeyPrefix+postID).Err()
+	if err != nil {
+		logger.Log.Error("Failed to break edit lease", zap.String("post_id", postID), zap.Error(err))
+		return common.ErrInternalServerError
+	}
+	return nil
+}
+
+// Get returns the active lease of a post, or nil when nobody holds it.
+func (r *EditLeaseRepositoryRedis) Get(ctx context.Context, postID string) (*domain.EditLease, error) {
+	key := editLeaseKeyPrefix + postID
+	pipe := r.Client.Pipeline()
+	fields := pipe.HGetAll(ctx, key)
+	ttl := pipe.PTTL(ctx, key)
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		logger.Log.Error("Failed to get edit lease", zap.String("post_id", postID), zap.Error(err))
+		return nil, common.ErrInternalServerError
+	}
+	values := fields.Val()
+	if values["holder_id"] == "" || ttl.Val() <= 0 {
+		return nil, nil
+	}
+	acquiredAt, err := strconv.ParseInt(values["acquired_at"], 10, 64)
+	if err != nil {
+		logger.Log.Error("Invalid edit lease acquired_at", zap.String("post_id", postID), zap.Error(err))
+		return nil, common.ErrInternalServerError
+	}
+	return &domain.EditLease{
+		PostID:     postID,
+		HolderID:   values["holder_id"],
+		HolderName: values["holder_name"],
+		AcquiredAt: time.UnixMilli(acquiredAt),
+		ExpiresAt:  time.Now().Add(ttl.Val()),
+	}, nil
+}
@@ -3,11 +3,14 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"livoir-blog/internal/domain"
 	"livoir-blog/pkg/common"
 	"livoir-blog/pkg/logger"
 	"livoir-blog/pkg/ulid"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/lib/pq"
 	"go.uber.org/zap"
@@ -24,12 +27,65 @@ func NewPostRepository(db *sql.DB) (domain.PostRepository, error) {
 	return &postRepository{db}, nil
 }
 
-func (r *postRepository) GetByID(ctx context.Context, id string) (*domain.PostDetail, error) {
+// postDetailColumns are the plain columns scanPostDetail reads. The slug column
+// is left open: published reads use the post's slug, drafts their own.
+const postDetailColumns = `p.id, p.current_version_id, %s, p.created_at, p.updated_at, p.unpublished_at, p.archived_at, p.deleted_at, pv.title, pv.content, pv.content_format, pv.source, pv.excerpt, pv.word_count, pv.reading_time_minutes, pv.meta_description, pv.canonical_url, pv.og_image, pv.og_title, pv.og_description, pv.noindex, pv.id, pv.version_number, pv.published_at`
+
+// postDetailAggregates follow postDetailColumns in every post detail query.
+const postDetailAggregates = `ARRAY(SELECT pa.administrator_id FROM post_authors pa WHERE pa.post_id = p.id ORDER BY pa.position), ARRAY(SELECT COALESCE(NULLIF(pa.display_name, ''), a.full_name) FROM post_authors pa JOIN administrators a ON pa.administrator_id = a.id WHERE pa.post_id = p.id ORDER BY pa.position), ARRAY(SELECT t.id FROM post_version_tags pvt JOIN tags t ON pvt.tag_id = t.id WHERE pvt.post_version_id = pv.id ORDER BY t.name), ARRAY(SELECT t.name FROM post_version_tags pvt JOIN tags t ON pvt.tag_id = t.id WHERE pvt.post_version_id = pv.id ORDER BY t.name), ARRAY(SELECT t.slug FROM post_version_tags pvt JOIN tags t ON pvt.tag_id = t.id WHERE pvt.post_version_id = pv.id ORDER BY t.name), ARRAY_AGG(COALESCE(c.id, '')), ARRAY_AGG(COALESCE(c.name, ''))`
+
+const (
+	currentVersionJoin = "p.current_version_id = pv.id"
+	anyVersionJoin     = "p.id = pv.post_id"
+)
+
+// postColumns are the columns scanned when a post is locked for update.
+const postColumns = "id, current_version_id, slug, created_at, updated_at, unpublished_at, archived_at, deleted_at"
+
+// postDetailQuery builds a post detail SELECT over the versions picked by
+// versionJoin. suffix follows the GROUP BY, for ordering and limits.
+func postDetailQuery(slugColumn, versionJoin, where, suffix string) string {
+	columns := fmt.Sprintf(postDetailColumns, slugColumn)
+	return strings.TrimSpace(fmt.Sprintf(`SELECT %s, %s FROM posts p JOIN post_versions pv ON %s LEFT JOIN post_version_categories pvc ON pv.id = pvc.post_version_id LEFT JOIN categories c ON pvc.category_id = c.id WHERE %s GROUP BY %s %s`, columns, postDetailAggregates, versionJoin, where, columns, suffix)) //#nosec G201
+}
+
+func scanPostDetail(row rowScanner) (*domain.PostDetail, error) {
 	var post domain.PostDetail
+	var authorIDs pq.StringArray
+	var authorNames pq.StringArray
+	var tagIDs pq.StringArray
+	var tagNames pq.StringArray
+	var tagSlugs pq.StringArray
 	var categoryIDs pq.StringArray
 	var categoryNames pq.StringArray
-	query := `SELECT p.id, p.current_version_id, p.created_at, p.updated_at, pv.title, pv.content, pv.version_number, ARRAY_AGG(COALESCE(c.id, '')), ARRAY_AGG(COALESCE(c.name, '')) FROM posts p JOIN post_versions pv ON p.id = pv.post_id LEFT JOIN post_version_categories pvc ON pv.id = pvc.post_version_id LEFT JOIN categories c ON pvc.category_id = c.id WHERE p.id = $1 GROUP BY p.id, p.current_version_id, p.created_at, p.updated_at, pv.title, pv.content, pv.version_number ORDER BY pv.version_number DESC LIMIT 1`
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&post.ID, &post.CurrentVersionID, &post.CreatedAt, &post.UpdatedAt, &post.Title, &post.Content, &post.VersionNumber, &categoryIDs, &categoryNames)
+	err := row.Scan(&post.ID, &post.CurrentVersionID, &post.Slug, &post.CreatedAt, &post.UpdatedAt, &post.UnpublishedAt, &post.ArchivedAt, &post.DeletedAt, &post.Title, &post.Content, &post.ContentFormat, &post.Source, &post.Excerpt, &post.WordCount, &post.ReadingTimeMinutes, &post.SEO.MetaDescription, &post.SEO.CanonicalURL, &post.SEO.OGImage, &post.SEO.OGTitle, &post.SEO.OGDescription, &post.SEO.NoIndex, &post.VersionID, &post.VersionNumber, &post.PublishedAt, &authorIDs, &authorNames, &tagIDs, &tagNames, &tagSlugs, &categoryIDs, &categoryNames)
+	if err != nil {
+		return nil, err
+	}
+	post.Archived = post.ArchivedAt != nil
+	post.Authors = toAuthors(authorIDs, authorNames)
+	post.Categories = toCategories(categoryIDs, categoryNames)
+	post.Tags = toTags(tagIDs, tagNames, tagSlugs)
+	return &post, nil
+}
+
+func (r *postRepository) GetByID(ctx context.Context, id string) (*domain.PostDetail, error) {
+	query := postDetailQuery("p.slug", currentVersionJoin, "p.id = $1 AND pv.published_at IS NOT NULL AND p.unpublished_at IS NULL AND p.deleted_at IS NULL", "")
+	return r.getPostDetail(ctx, query, id)
+}
+
+func (r *postRepository) GetDraftByID(ctx context.Context, id string) (*domain.PostDetail, error) {
+	query := postDetailQuery("pv.slug", anyVersionJoin, "p.id = $1 AND p.deleted_at IS NULL", "ORDER BY pv.version_number DESC LIMIT 1")
+	return r.getPostDetail(ctx, query, id)
+}
+
+func (r *postRepository) GetBySlug(ctx context.Context, slug string) (*domain.PostDetail, error) {
+	query := postDetailQuery("p.slug", currentVersionJoin, "p.slug = $1 AND pv.published_at IS NOT NULL AND p.unpublished_at IS NULL AND p.deleted_at IS NULL", "")
+	return r.getPostDetail(ctx, query, slug)
+}
+
+func (r *postRepository) getPostDetail(ctx context.Context, query string, args ...interface{}) (*domain.PostDetail, error) {
+	post, err := scanPostDetail(r.db.QueryRowContext(ctx, query, args...))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, common.ErrPostNotFound
@@ -37,23 +93,72 @@ func (r *postRepository) GetByID(ctx context.Context, id string) (*domain.PostDe
 		logger.Log.Error("Failed to get post by id", zap.Error(err))
 		return nil, common.ErrInternalServerError
 	}
-	for i := range categoryIDs {
-		if categoryIDs[i] == "" {
-			continue
+	return post, nil
+}
+
+func (r *postRepository) List(ctx context.Context, filter *domain.PostListFilter) ([]*domain.PostDetail, error) {
+	conditions := []string{"pv.published_at IS NOT NULL", "p.unpublished_at IS NULL", "p.archived_at IS NULL", "p.deleted_at IS NULL"}
+	args := []interface{}{}
+	if filter.BeforeID != "" {
+		args = append(args, filter.BeforeID)
+		conditions = append(conditions, fmt.Sprintf("p.id < $%d", len(args)))
+	}
+	if filter.CategoryID != "" && filter.IncludeDescendants {
+		args = append(args, filter.CategoryID)
+		conditions = append(conditions, fmt.Sprintf("EXISTS (SELECT 1 FROM post_version_categories fpvc WHERE fpvc.post_version_id = pv.id AND fpvc.category_id IN (WITH RECURSIVE descendants AS (SELECT id FROM categories WHERE id = $%d UNION ALL SELECT c.id FROM categories c JOIN descendants d ON c.parent_id = d.id) SELECT id FROM descendants))", len(args)))
+	} else if filter.CategoryID != "" {
+		args = append(args, filter.CategoryID)
+		conditions = append(conditions, fmt.Sprintf("EXISTS (SELECT 1 FROM post_version_categories fpvc WHERE fpvc.post_version_id = pv.id AND fpvc.category_id = $%d)", len(args)))
+	}
+	if filter.TagID != "" {
+		args = append(args, filter.TagID)
+		conditions = append(conditions, fmt.Sprintf("EXISTS (SELECT 1 FROM post_version_tags fpvt WHERE fpvt.post_version_id = pv.id AND fpvt.tag_id = $%d)", len(args)))
+	}
+	if filter.AuthorID != "" {
+		args = append(args, filter.AuthorID)
+		conditions = append(conditions, fmt.Sprintf("EXISTS (SELECT 1 FROM post_authors fpa WHERE fpa.post_id = p.id AND fpa.administrator_id = $%d)", len(args)))
+	}
+	if filter.PublishedAfter != nil {
+		args = append(args, *filter.PublishedAfter)
+		conditions = append(conditions, fmt.Sprintf("pv.published_at >= $%d", len(args)))
+	}
+	if filter.PublishedBefore != nil {
+		args = append(args, *filter.PublishedBefore)
+		conditions = append(conditions, fmt.Sprintf("pv.published_at < $%d", len(args)))
+	}
+	args = append(args, filter.Limit)
+	query := postDetailQuery("p.slug", currentVersionJoin, strings.Join(conditions, " AND "), fmt.Sprintf("ORDER BY p.id DESC LIMIT $%d", len(args)))
+	return r.listPostDetails(ctx, query, args...)
+}
+
+func (r *postRepository) listPostDetails(ctx context.Context, query string, args ...interface{}) ([]*domain.PostDetail, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Log.Error("Failed to list posts", zap.Error(err))
+		return nil, common.ErrInternalServerError
+	}
+	defer rows.Close()
+	posts := []*domain.PostDetail{}
+	for rows.Next() {
+		post, err := scanPostDetail(rows)
+		if err != nil {
+			logger.Log.Error("Failed to scan post", zap.Error(err))
+			return nil, common.ErrInternalServerError
 		}
-		post.Categories = append(post.Categories, domain.Category{
-			ID:   categoryIDs[i],
-			Name: categoryNames[i],
-		})
+		posts = append(posts, post)
 	}
-	return &post, nil
+	if err := rows.Err(); err != nil {
+		logger.Log.Error("Failed to iterate posts", zap.Error(err))
+		return nil, common.ErrInternalServerError
+	}
+	return posts, nil
 }
 
 func (r *postRepository) Create(ctx context.Context, tx domain.Transaction, post *domain.Post) error {
 	post.ID = ulid.New()
 	sqlTx := tx.GetTx()
-	query := `INSERT INTO posts (id, created_at, updated_at) VALUES ($1, $2, $3)`
-	result, err := sqlTx.ExecContext(ctx, query, post.ID, post.CreatedAt, post.UpdatedAt)
+	query := `INSERT INTO posts (id, slug, created_at, updated_at) VALUES ($1, $2, $3, $4)`
+	result, err := sqlTx.ExecContext(ctx, query, post.ID, post.Slug, post.CreatedAt, post.UpdatedAt)
 	if err != nil {
 		logger.Log.Error("Failed to create post", zap.Error(err))
 		return common.ErrInternalServerError
@@ -71,8 +176,8 @@ func (r *postRepository) Create(ctx context.Context, tx domain.Transaction, post
 
 func (r *postRepository) Update(ctx context.Context, tx domain.Transaction, post *domain.Post) error {
 	sqlTx := tx.GetTx()
-	query := `UPDATE posts SET current_version_id = $1, updated_at = $2 WHERE id = $3`
-	result, err := sqlTx.ExecContext(ctx, query, post.CurrentVersionID, post.UpdatedAt, post.ID)
+	query := `UPDATE posts SET current_version_id = $1, slug = $2, updated_at = $3, unpublished_at = $4, archived_at = $5, deleted_at = $6 WHERE id = $7`
+	result, err := sqlTx.ExecContext(ctx, query, post.CurrentVersionID, post.Slug, post.UpdatedAt, post.UnpublishedAt, post.ArchivedAt, post.DeletedAt, post.ID)
 	if err != nil {
 		logger.Log.Error("Failed to update post", zap.Error(err))
 		return common.ErrInternalServerError
@@ -89,17 +194,135 @@ func (r *postRepository) Update(ctx context.Context, tx domain.Transaction, post
 }
 
 func (r *postRepository) GetByIDForUpdate(ctx context.Context, tx domain.Transaction, id string) (*domain.Post, error) {
+	return r.getForUpdate(ctx, tx, "SELECT "+postColumns+" FROM posts WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", id)
+}
+
+func (r *postRepository) GetDeletedByIDForUpdate(ctx context.Context, tx domain.Transaction, id string) (*domain.Post, error) {
+	return r.getForUpdate(ctx, tx, "SELECT "+postColumns+" FROM posts WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE", id)
+}
+
+func (r *postRepository) getForUpdate(ctx context.Context, tx domain.Transaction, query string, id string) (*domain.Post, error) {
 	sqlTx := tx.GetTx()
 	post := &domain.Post{}
-	err := sqlTx.QueryRowContext(ctx, "SELECT id, current_version_id, created_at, updated_at FROM posts WHERE id = $1 FOR UPDATE", id).
-		Scan(&post.ID, &post.CurrentVersionID, &post.CreatedAt, &post.UpdatedAt)
+	err := sqlTx.QueryRowContext(ctx, query, id).
+		Scan(&post.ID, &post.CurrentVersionID, &post.Slug, &post.CreatedAt, &post.UpdatedAt, &post.UnpublishedAt, &post.ArchivedAt, &post.DeletedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			logger.Log.Error("No post versions found for post id", zap.String("id", id))
+			logger.Log.Error("No post found for id", zap.String("id", id))
 			return nil, common.ErrPostNotFound
 		}
-		logger.Log.Error("Failed to get latest post by id for update", zap.Error(err))
+		logger.Log.Error("Failed to get post by id for update", zap.Error(err))
 		return nil, common.ErrInternalServerError
 	}
 	return post, nil
 }
+
+// ListDeleted returns the posts in the trash with their latest version, most
+// recently created first.
+func (r *postRepository) ListDeleted(ctx context.Context, filter *domain.PostListFilter) ([]*domain.PostDetail, error) {
+	conditions := []string{"p.deleted_at IS NOT NULL", "pv.version_number = (SELECT MAX(lpv.version_number) FROM post_versions lpv WHERE lpv.post_id = p.id)"}
+	args := []interface{}{}
+	if filter.BeforeID != "" {
+		args = append(args, filter.BeforeID)
+		conditions = append(conditions, fmt.Sprintf("p.id < $%d", len(args)))
+	}
+	args = append(args, filter.Limit)
+	query := postDetailQuery("pv.slug", anyVersionJoin, strings.Join(conditions, " AND "), fmt.Sprintf("ORDER BY p.id DESC LIMIT $%d", len(args)))
+	return r.listPostDetails(ctx, query, args...)
+}
+
+// PurgeDeleted permanently removes posts that were moved to the trash before
+// the given time, together with their versions, category links, old slugs and
+// authors.
+func (r *postRepository) PurgeDeleted(ctx context.Context, tx domain.Transaction, before time.Time) (int64, error) {
+	sqlTx := tx.GetTx()
+	var ids pq.StringArray
+	err := sqlTx.QueryRowContext(ctx, "SELECT ARRAY(SELECT id FROM posts WHERE deleted_at IS NOT NULL AND deleted_at < $1 FOR UPDATE SKIP LOCKED)", before).Scan(&ids)
+	if err != nil {
+		logger.Log.Error("Failed to select posts to purge", zap.Error(err))
+		return 0, common.ErrInternalServerError
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	queries := []string{
+		"DELETE FROM post_search_documents WHERE post_id = ANY($1)",
+		"DELETE FROM post_version_categories WHERE post_version_id IN (SELECT id FROM post_versions WHERE post_id = ANY($1))",
+		"DELETE FROM post_versions WHERE post_id = ANY($1)",
+		"DELETE FROM post_slugs WHERE post_id = ANY($1)",
+		"DELETE FROM post_authors WHERE post_id = ANY($1)",
+	}
+	for _, query := range queries {
+		_, err = sqlTx.ExecContext(ctx, query, ids)
+		if err != nil {
+			logger.Log.Error("Failed to purge post data", zap.Error(err))
+			return 0, common.ErrInternalServerError
+		}
+	}
+	result, err := sqlTx.ExecContext(ctx, "DELETE FROM posts WHERE id = ANY($1)", ids)
+	if err != nil {
+		logger.Log.Error("Failed to purge posts", zap.Error(err))
+		return 0, common.ErrInternalServerError
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Log.Error("Failed to get rows affected", zap.Error(err))
+		return 0, common.ErrInternalServerError
+	}
+	return rowsAffected, nil
+}
+
+// ReplaceAuthors sets the authors of a post in the given order.
+func (r *postRepository) ReplaceAuthors(ctx context.Context, tx domain.Transaction, postID string, authors []domain.PostAuthorDTO) error {
+	sqlTx := tx.GetTx()
+	_, err := sqlTx.ExecContext(ctx, "DELETE FROM post_authors WHERE post_id = $1", postID)
+	if err != nil {
+		logger.Log.Error("Failed to delete post authors", zap.Error(err))
+		return common.ErrInternalServerError
+	}
+	for position, author := range authors {
+		_, err = sqlTx.ExecContext(ctx, "INSERT INTO post_authors (post_id, administrator_id, position, display_name) VALUES ($1, $2, $3, $4)", postID, author.AdministratorID, position, author.DisplayName)
+		if err != nil {
+			logger.Log.Error("Failed to insert post author", zap.Error(err))
+			return common.ErrInternalServerError
+		}
+	}
+	return nil
+}
+
+func toAuthors(ids, names pq.StringArray) []domain.Author {
+	authors := make([]domain.Author, 0, len(ids))
+	for i := range ids {
+		authors = append(authors, domain.Author{
+			ID:          ids[i],
+			DisplayName: names[i],
+		})
+	}
+	return authors
+}
+
+func toCategories(ids, names pq.StringArray) []domain.Category {
+	var categories []domain.Category
+	for i := range ids {
+		if ids[i] == "" {
+			continue
+		}
+		categories = append(categories, domain.Category{
+			ID:   ids[i],
+			Name: names[i],
+		})
+	}
+	return categories
+}
+
+func toTags(ids, names, slugs pq.StringArray) []domain.Tag {
+	tags := make([]domain.Tag, 0, len(ids))
+	for i := range ids {
+		tags = append(tags, domain.Tag{
+			ID:   ids[i],
+			Name: names[i],
+			Slug: slugs[i],
+		})
+	}
+	return tags
+}
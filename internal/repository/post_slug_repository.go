@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"livoir-blog/internal/domain"
+	"livoir-blog/pkg/common"
+	"livoir-blog/pkg/logger"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+type postSlugRepository struct {
+	db *sql.DB
+}
+
+func NewPostSlugRepository(db *sql.DB) (domain.PostSlugRepository, error) {
+	if db == nil {
+		return nil, common.NewCustomError(http.StatusInternalServerError, "db is nil")
+	}
+	return &postSlugRepository{db: db}, nil
+}
+
+func (r *postSlugRepository) Create(ctx context.Context, tx domain.Transaction, postSlug *domain.PostSlug) error {
+	sqlTx := tx.GetTx()
+	query := `INSERT INTO post_slugs (slug, post_id, created_at) VALUES ($1, $2, $3) ON CONFLICT (slug) DO UPDATE SET post_id = EXCLUDED.post_id, created_at = EXCLUDED.created_at`
+	result, err := sqlTx.ExecContext(ctx, query, postSlug.Slug, postSlug.PostID, postSlug.CreatedAt)
+	if err != nil {
+		logger.Log.Error("Failed to create post slug", zap.Error(err))
+		return common.ErrInternalServerError
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Log.Error("Failed to get rows affected", zap.Error(err))
+		return common.ErrInternalServerError
+	}
+	if rowsAffected == 0 {
+		return common.NewCustomError(http.StatusInternalServerError, "failed to create post slug")
+	}
+	return nil
+}
+
+func (r *postSlugRepository) Delete(ctx context.Context, tx domain.Transaction, postID, slug string) error {
+	sqlTx := tx.GetTx()
+	_, err := sqlTx.ExecContext(ctx, "DELETE FROM post_slugs WHERE slug = $1 AND post_id = $2", slug, postID)
+	if err != nil {
+		logger.Log.Error("Failed to delete post slug", zap.Error(err))
+		return common.ErrInternalServerError
+	}
+	return nil
+}
+
+func (r *postSlugRepository) GetCurrentSlug(ctx context.Context, slug string) (string, error) {
+	query := `SELECT p.slug FROM post_slugs ps JOIN posts p ON ps.post_id = p.id JOIN post_versions pv ON p.current_version_id = pv.id WHERE ps.slug = $1 AND pv.published_at IS NOT NULL AND p.unpublished_at IS NULL AND p.archived_at IS NULL AND p.deleted_at IS NULL`
+	var currentSlug string
+	err := r.db.QueryRowContext(ctx, query, slug).Scan(&currentSlug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", common.ErrPostNotFound
+		}
+		logger.Log.Error("Failed to get current slug", zap.Error(err))
+		return "", common.ErrInternalServerError
+	}
+	return currentSlug, nil
+}
+
+func (r *postSlugRepository) IsTaken(ctx context.Context, tx domain.Transaction, slug, postID string) (bool, error) {
+	sqlTx := tx.GetTx()
+	query := `SELECT EXISTS (SELECT 1 FROM posts WHERE slug = $1 AND id <> $2) OR EXISTS (SELECT 1 FROM post_versions WHERE slug = $1 AND post_id <> $2) OR EXISTS (SELECT 1 FROM post_slugs WHERE slug = $1 AND post_id <> $2)`
+	var taken bool
+	err := sqlTx.QueryRowContext(ctx, query, slug, postID).Scan(&taken)
+	if err != nil {
+		logger.Log.Error("Failed to check slug availability", zap.Error(err))
+		return false, common.ErrInternalServerError
+	}
+	return taken, nil
+}
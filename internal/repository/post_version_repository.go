@@ -9,10 +9,17 @@ import (
 	"livoir-blog/pkg/logger"
 	"livoir-blog/pkg/ulid"
 	"net/http"
+	"time"
 
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 )
 
+const postVersionColumns = `id, version_number, post_id, created_at, title, slug, content, content_format, source, excerpt, custom_excerpt, word_count, reading_time_minutes, meta_description, canonical_url, og_image, og_title, og_description, noindex, editor_id, published_at, publish_at`
+
+// activePostVersion keeps versions of posts in the trash out of every read.
+const activePostVersion = `post_id IN (SELECT id FROM posts WHERE deleted_at IS NULL)`
+
 type postVersionRepository struct {
 	db *sql.DB
 }
@@ -27,8 +34,8 @@ func NewPostVersionRepository(db *sql.DB) (domain.PostVersionRepository, error)
 func (r *postVersionRepository) Create(ctx context.Context, tx domain.Transaction, postVersion *domain.PostVersion) error {
 	postVersion.ID = ulid.New()
 	sqlTx := tx.GetTx()
-	query := `INSERT INTO post_versions (id, version_number, post_id, created_at, title, content) VALUES ($1, $2, $3, $4, $5, $6)`
-	result, err := sqlTx.ExecContext(ctx, query, postVersion.ID, postVersion.VersionNumber, postVersion.PostID, postVersion.CreatedAt, postVersion.Title, postVersion.Content)
+	query := `INSERT INTO post_versions (id, version_number, post_id, created_at, title, slug, content, content_format, source, excerpt, custom_excerpt, word_count, reading_time_minutes, meta_description, canonical_url, og_image, og_title, og_description, noindex, editor_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)`
+	result, err := sqlTx.ExecContext(ctx, query, postVersion.ID, postVersion.VersionNumber, postVersion.PostID, postVersion.CreatedAt, postVersion.Title, postVersion.Slug, postVersion.Content, postVersion.ContentFormat, postVersion.Source, postVersion.Excerpt, postVersion.CustomExcerpt, postVersion.WordCount, postVersion.ReadingTimeMinutes, postVersion.SEO.MetaDescription, postVersion.SEO.CanonicalURL, postVersion.SEO.OGImage, postVersion.SEO.OGTitle, postVersion.SEO.OGDescription, postVersion.SEO.NoIndex, postVersion.EditorID)
 	if err != nil {
 		logger.Log.Error("Failed to create post version", zap.Error(err))
 		return common.NewCustomError(http.StatusInternalServerError, "error while creating post version")
@@ -46,8 +53,8 @@ func (r *postVersionRepository) Create(ctx context.Context, tx domain.Transactio
 
 func (r *postVersionRepository) Update(ctx context.Context, tx domain.Transaction, postVersion *domain.PostVersion) error {
 	sqlTx := tx.GetTx()
-	query := `UPDATE post_versions SET title = $2, content = $3, published_at = $4, version_number = $5 WHERE id = $1`
-	result, err := sqlTx.ExecContext(ctx, query, postVersion.ID, postVersion.Title, postVersion.Content, postVersion.PublishedAt, postVersion.VersionNumber)
+	query := `UPDATE post_versions SET title = $2, content = $3, published_at = $4, version_number = $5, slug = $6, publish_at = $7, content_format = $8, source = $9, excerpt = $10, custom_excerpt = $11, word_count = $12, reading_time_minutes = $13, meta_description = $14, canonical_url = $15, og_image = $16, og_title = $17, og_description = $18, noindex = $19, editor_id = $20 WHERE id = $1`
+	result, err := sqlTx.ExecContext(ctx, query, postVersion.ID, postVersion.Title, postVersion.Content, postVersion.PublishedAt, postVersion.VersionNumber, postVersion.Slug, postVersion.PublishAt, postVersion.ContentFormat, postVersion.Source, postVersion.Excerpt, postVersion.CustomExcerpt, postVersion.WordCount, postVersion.ReadingTimeMinutes, postVersion.SEO.MetaDescription, postVersion.SEO.CanonicalURL, postVersion.SEO.OGImage, postVersion.SEO.OGTitle, postVersion.SEO.OGDescription, postVersion.SEO.NoIndex, postVersion.EditorID)
 	if err != nil {
 		logger.Log.Error("Failed to update post version", zap.Error(err))
 		return common.NewCustomError(http.StatusBadRequest, "error while updating post version")
@@ -66,8 +73,8 @@ func (r *postVersionRepository) Update(ctx context.Context, tx domain.Transactio
 func (r *postVersionRepository) GetLatestByPostIDForUpdate(ctx context.Context, tx domain.Transaction, postID string) (*domain.PostVersion, error) {
 	sqlTx := tx.GetTx()
 	postVersion := &domain.PostVersion{}
-	err := sqlTx.QueryRowContext(ctx, "SELECT id, version_number, post_id, created_at, title, content, published_at FROM post_versions WHERE post_id = $1 ORDER BY version_number DESC LIMIT 1 FOR UPDATE", postID).
-		Scan(&postVersion.ID, &postVersion.VersionNumber, &postVersion.PostID, &postVersion.CreatedAt, &postVersion.Title, &postVersion.Content, &postVersion.PublishedAt)
+	row := sqlTx.QueryRowContext(ctx, "SELECT "+postVersionColumns+" FROM post_versions WHERE "+activePostVersion+" AND post_id = $1 ORDER BY version_number DESC LIMIT 1 FOR UPDATE", postID)
+	err := scanPostVersion(row, postVersion)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			logger.Log.Error("No post versions found for post id", zap.String("postID", postID))
@@ -101,8 +108,8 @@ func (r *postVersionRepository) Delete(ctx context.Context, tx domain.Transactio
 func (r *postVersionRepository) GetByIDForUpdate(ctx context.Context, tx domain.Transaction, id string) (*domain.PostVersion, error) {
 	sqlTx := tx.GetTx()
 	postVersion := &domain.PostVersion{}
-	err := sqlTx.QueryRowContext(ctx, "SELECT id, version_number, post_id, created_at, title, content, published_at FROM post_versions WHERE id = $1 FOR UPDATE", id).
-		Scan(&postVersion.ID, &postVersion.VersionNumber, &postVersion.PostID, &postVersion.CreatedAt, &postVersion.Title, &postVersion.Content, &postVersion.PublishedAt)
+	row := sqlTx.QueryRowContext(ctx, "SELECT "+postVersionColumns+" FROM post_versions WHERE "+activePostVersion+" AND id = $1 FOR UPDATE", id)
+	err := scanPostVersion(row, postVersion)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			logger.Log.Error("No post versions found for id", zap.String("id", id))
@@ -115,10 +122,10 @@ func (r *postVersionRepository) GetByIDForUpdate(ctx context.Context, tx domain.
 }
 
 func (r *postVersionRepository) GetByID(ctx context.Context, id string) (*domain.PostVersion, error) {
-	query := `SELECT id, version_number, post_id, created_at, title, content, published_at FROM post_versions WHERE id = $1`
+	query := `SELECT ` + postVersionColumns + ` FROM post_versions WHERE ` + activePostVersion + ` AND id = $1`
 	row := r.db.QueryRowContext(ctx, query, id)
 	var postVersion domain.PostVersion
-	err := row.Scan(&postVersion.ID, &postVersion.VersionNumber, &postVersion.PostID, &postVersion.CreatedAt, &postVersion.Title, &postVersion.Content, &postVersion.PublishedAt)
+	err := scanPostVersion(row, &postVersion)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, common.ErrPostVersionNotFound
@@ -128,3 +135,117 @@ func (r *postVersionRepository) GetByID(ctx context.Context, id string) (*domain
 	}
 	return &postVersion, nil
 }
+
+func (r *postVersionRepository) GetByPostIDAndVersionNumber(ctx context.Context, postID string, versionNumber int64) (*domain.PostVersion, error) {
+	query := `SELECT ` + postVersionColumns + ` FROM post_versions WHERE ` + activePostVersion + ` AND post_id = $1 AND version_number = $2`
+	row := r.db.QueryRowContext(ctx, query, postID, versionNumber)
+	var postVersion domain.PostVersion
+	err := scanPostVersion(row, &postVersion)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, common.ErrPostVersionNotFound
+		}
+		logger.Log.Error("Failed to get post version by post id and version number", zap.Error(err))
+		return nil, common.NewCustomError(http.StatusInternalServerError, "error while trying to get post version by version number")
+	}
+	return &postVersion, nil
+}
+
+func (r *postVersionRepository) GetByPostIDAndVersionNumberForUpdate(ctx context.Context, tx domain.Transaction, postID string, versionNumber int64) (*domain.PostVersion, error) {
+	sqlTx := tx.GetTx()
+	postVersion := &domain.PostVersion{}
+	row := sqlTx.QueryRowContext(ctx, "SELECT "+postVersionColumns+" FROM post_versions WHERE "+activePostVersion+" AND post_id = $1 AND version_number = $2 FOR UPDATE", postID, versionNumber)
+	err := scanPostVersion(row, postVersion)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, common.ErrPostVersionNotFound
+		}
+		logger.Log.Error("Failed to get post version by version number for update", zap.Error(err))
+		return nil, common.NewCustomError(http.StatusInternalServerError, "error while trying to get post version by version number for update")
+	}
+	return postVersion, nil
+}
+
+// GetNextDue returns the earliest scheduled draft that is due, leaving out
+// excludeIDs. It takes no locks: callers lock the post before the version, as
+// edits do, and check again that the version is still due.
+func (r *postVersionRepository) GetNextDue(ctx context.Context, now time.Time, excludeIDs []string) (*domain.PostVersion, error) {
+	if excludeIDs == nil {
+		excludeIDs = []string{}
+	}
+	postVersion := &domain.PostVersion{}
+	row := r.db.QueryRowContext(ctx, "SELECT "+postVersionColumns+" FROM post_versions WHERE "+activePostVersion+" AND published_at IS NULL AND publish_at <= $1 AND NOT (id = ANY($2)) ORDER BY publish_at, id LIMIT 1", now, pq.Array(excludeIDs))
+	err := scanPostVersion(row, postVersion)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		logger.Log.Error("Failed to get due post version", zap.Error(err))
+		return nil, common.NewCustomError(http.StatusInternalServerError, "error while trying to get due post version")
+	}
+	return postVersion, nil
+}
+
+func (r *postVersionRepository) ListByPostID(ctx context.Context, postID string) ([]*domain.PostVersion, error) {
+	query := `SELECT ` + postVersionColumns + ` FROM post_versions WHERE ` + activePostVersion + ` AND post_id = $1 ORDER BY version_number DESC`
+	rows, err := r.db.QueryContext(ctx, query, postID)
+	if err != nil {
+		logger.Log.Error("Failed to list post versions", zap.Error(err))
+		return nil, common.NewCustomError(http.StatusInternalServerError, "error while trying to list post versions")
+	}
+	defer rows.Close()
+	var postVersions []*domain.PostVersion
+	for rows.Next() {
+		var postVersion domain.PostVersion
+		err := scanPostVersion(rows, &postVersion)
+		if err != nil {
+			logger.Log.Error("Failed to scan post version", zap.Error(err))
+			return nil, common.NewCustomError(http.StatusInternalServerError, "error while trying to list post versions")
+		}
+		postVersions = append(postVersions, &postVersion)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Log.Error("Failed to iterate post versions", zap.Error(err))
+		return nil, common.NewCustomError(http.StatusInternalServerError, "error while trying to list post versions")
+	}
+	return postVersions, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func (r *postVersionRepository) SaveRevision(ctx context.Context, tx domain.Transaction, revision *domain.PostVersionRevision, keep int) error {
+	sqlTx := tx.GetTx()
+	query := `INSERT INTO post_version_revisions (post_version_id, etag, title, slug, content_format, source, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (post_version_id, etag) DO UPDATE SET created_at = EXCLUDED.created_at`
+	_, err := sqlTx.ExecContext(ctx, query, revision.PostVersionID, revision.ETag, revision.Title, revision.Slug, revision.ContentFormat, revision.Source, revision.CreatedAt)
+	if err != nil {
+		logger.Log.Error("Failed to save post version revision", zap.Error(err))
+		return common.ErrInternalServerError
+	}
+	query = `DELETE FROM post_version_revisions WHERE post_version_id = $1 AND etag NOT IN (SELECT etag FROM post_version_revisions WHERE post_version_id = $1 ORDER BY created_at DESC LIMIT $2)`
+	_, err = sqlTx.ExecContext(ctx, query, revision.PostVersionID, keep)
+	if err != nil {
+		logger.Log.Error("Failed to prune post version revisions", zap.Error(err))
+		return common.ErrInternalServerError
+	}
+	return nil
+}
+
+func (r *postVersionRepository) GetRevision(ctx context.Context, postVersionID, etag string) (*domain.PostVersionRevision, error) {
+	query := `SELECT post_version_id, etag, title, slug, content_format, source, created_at FROM post_version_revisions WHERE post_version_id = $1 AND etag = $2`
+	var revision domain.PostVersionRevision
+	err := r.db.QueryRowContext(ctx, query, postVersionID, etag).Scan(&revision.PostVersionID, &revision.ETag, &revision.Title, &revision.Slug, &revision.ContentFormat, &revision.Source, &revision.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, common.ErrPostVersionNotFound
+		}
+		logger.Log.Error("Failed to get post version revision", zap.Error(err))
+		return nil, common.ErrInternalServerError
+	}
+	return &revision, nil
+}
+
+func scanPostVersion(row rowScanner, postVersion *domain.PostVersion) error {
+	return row.Scan(&postVersion.ID, &postVersion.VersionNumber, &postVersion.PostID, &postVersion.CreatedAt, &postVersion.Title, &postVersion.Slug, &postVersion.Content, &postVersion.ContentFormat, &postVersion.Source, &postVersion.Excerpt, &postVersion.CustomExcerpt, &postVersion.WordCount, &postVersion.ReadingTimeMinutes, &postVersion.SEO.MetaDescription, &postVersion.SEO.CanonicalURL, &postVersion.SEO.OGImage, &postVersion.SEO.OGTitle, &postVersion.SEO.OGDescription, &postVersion.SEO.NoIndex, &postVersion.EditorID, &postVersion.PublishedAt, &postVersion.PublishAt)
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"livoir-blog/internal/domain"
+	"livoir-blog/pkg/common"
+	"livoir-blog/pkg/logger"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+type searchRepository struct {
+	db *sql.DB
+}
+
+func NewSearchRepository(db *sql.DB) (domain.SearchRepository, error) {
+	if db == nil {
+		return nil, common.NewCustomError(http.StatusInternalServerError, "db is nil")
+	}
+	return &searchRepository{db: db}, nil
+}
+
+func (r *searchRepository) Index(ctx context.Context, tx domain.Transaction, document *domain.SearchDocument) error {
+	sqlTx := tx.GetTx()
+	query := `INSERT INTO post_search_documents (post_id, post_version_id, language, title, body, document, updated_at) VALUES ($1, $2, $3::regconfig, $4, $5, setweight(to_tsvector($3::regconfig, $4), 'A') || setweight(to_tsvector($3::regconfig, $5), 'B'), $6) ON CONFLICT (post_id) DO UPDATE SET post_version_id = EXCLUDED.post_version_id, language = EXCLUDED.language, title = EXCLUDED.title, body = EXCLUDED.body, document = EXCLUDED.document, updated_at = EXCLUDED.updated_at`
+	_, err := sqlTx.ExecContext(ctx, query, document.PostID, document.PostVersionID, document.Language, document.Title, document.Body, document.UpdatedAt)
+	if err != nil {
+		logger.Log.Error("Failed to index post", zap.String("post_id", document.PostID), zap.Error(err))
+		return common.ErrInternalServerError
+	}
+	return nil
+}
+
+func (r *searchRepository) Search(ctx context.Context, filter *domain.SearchFilter) ([]domain.SearchResult, error) {
+	headlineOptions := fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxFragments=2, MaxWords=30, MinWords=10`, domain.SnippetStartSel, domain.SnippetStopSel)
+	args := []interface{}{filter.Language, filter.Query, headlineOptions}
+	categoryCondition := ""
+	if filter.CategoryID != "" {
+		args = append(args, filter.CategoryID)
+		categoryCondition = fmt.Sprintf(" AND EXISTS (SELECT 1 FROM post_version_categories pvc WHERE pvc.post_version_id = pv.id AND pvc.category_id = $%d)", len(args))
+	}
+	args = append(args, filter.Limit, filter.Offset)
+	// Title matches weigh more than body matches through the A and B weights
+	query := fmt.Sprintf(`SELECT p.id, p.slug, pv.title, ts_headline(psd.language, psd.body, q.query, $3), ts_rank(psd.document, q.query) AS rank, pv.published_at FROM post_search_documents psd JOIN posts p ON psd.post_id = p.id JOIN post_versions pv ON p.current_version_id = pv.id AND psd.post_version_id = pv.id CROSS JOIN (SELECT websearch_to_tsquery($1::regconfig, $2) AS query) q WHERE psd.document @@ q.query AND pv.published_at IS NOT NULL AND p.unpublished_at IS NULL AND p.deleted_at IS NULL AND p.archived_at IS NULL%s ORDER BY rank DESC, p.id DESC LIMIT $%d OFFSET $%d`, categoryCondition, len(args)-1, len(args))
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Log.Error("Failed to search posts", zap.Error(err))
+		return nil, common.ErrInternalServerError
+	}
+	defer rows.Close()
+	results := []domain.SearchResult{}
+	for rows.Next() {
+		var result domain.SearchResult
+		err := rows.Scan(&result.PostID, &result.Slug, &result.Title, &result.Snippet, &result.Rank, &result.PublishedAt)
+		if err != nil {
+			logger.Log.Error("Failed to scan search result", zap.Error(err))
+			return nil, common.ErrInternalServerError
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Log.Error("Failed to iterate search results", zap.Error(err))
+		return nil, common.ErrInternalServerError
+	}
+	return results, nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func (r *searchRepository) Suggest(ctx context.Context, query string, limit int) ([]domain.Suggestion, error) {
+	// Exact prefixes score a full point on top of the similarity, word prefixes half
+	score := func(column string) string {
+		return fmt.Sprintf(`word_similarity($1, lower(%[1]s)) + CASE WHEN lower(%[1]s) LIKE $2 || '%%' THEN 1 WHEN lower(%[1]s) LIKE '%%' || ' ' || $2 || '%%' THEN 0.5 ELSE 0 END`, column)
+	}
+	matches := func(column string) string {
+		return fmt.Sprintf(`($1 <%% lower(%[1]s) OR lower(%[1]s) LIKE '%%' || $2 || '%%')`, column)
+	}
+	sqlQuery := fmt.Sprintf(`SELECT type, id, slug, text, score FROM (SELECT '%s' AS type, p.id, p.slug, psd.title AS text, %s AS score FROM post_search_documents psd JOIN posts p ON psd.post_id = p.id JOIN post_versions pv ON p.current_version_id = pv.id AND psd.post_version_id = pv.id WHERE %s AND pv.published_at IS NOT NULL AND p.unpublished_at IS NULL AND p.deleted_at IS NULL AND p.archived_at IS NULL UNION ALL SELECT '%s', c.id, '', c.name, %s FROM categories c WHERE %s) suggestions ORDER BY score DESC, text LIMIT $3`,
+		domain.SuggestionTypePost, score("psd.title"), matches("psd.title"),
+		domain.SuggestionTypeCategory, score("c.name"), matches("c.name"))
+	rows, err := r.db.QueryContext(ctx, sqlQuery, query, likeEscaper.Replace(query), limit)
+	if err != nil {
+		logger.Log.Error("Failed to suggest search terms", zap.Error(err))
+		return nil, common.ErrInternalServerError
+	}
+	defer rows.Close()
+	suggestions := []domain.Suggestion{}
+	for rows.Next() {
+		var suggestion domain.Suggestion
+		err := rows.Scan(&suggestion.Type, &suggestion.ID, &suggestion.Slug, &suggestion.Text, &suggestion.Score)
+		if err != nil {
+			logger.Log.Error("Failed to scan suggestion", zap.Error(err))
+			return nil, common.ErrInternalServerError
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Log.Error("Failed to iterate suggestions", zap.Error(err))
+		return nil, common.ErrInternalServerError
+	}
+	return suggestions, nil
+}
+
+func (r *searchRepository) ListStale(ctx context.Context, language string) ([]string, error) {
+	query := `SELECT pv.id FROM posts p JOIN post_versions pv ON p.current_version_id = pv.id LEFT JOIN post_search_documents psd ON psd.post_id = p.id WHERE pv.published_at IS NOT NULL AND p.unpublished_at IS NULL AND p.archived_at IS NULL AND p.deleted_at IS NULL AND (psd.post_id IS NULL OR psd.language <> $1::regconfig) ORDER BY p.id`
+	rows, err := r.db.QueryContext(ctx, query, language)
+	if err != nil {
+		logger.Log.Error("Failed to list stale search documents", zap.Error(err))
+		return nil, common.ErrInternalServerError
+	}
+	defer rows.Close()
+	var postVersionIDs []string
+	for rows.Next() {
+		var postVersionID string
+		err := rows.Scan(&postVersionID)
+		if err != nil {
+			logger.Log.Error("Failed to scan stale search document", zap.Error(err))
+			return nil, common.ErrInternalServerError
+		}
+		postVersionIDs = append(postVersionIDs, postVersionID)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Log.Error("Failed to iterate stale search documents", zap.Error(err))
+		return nil, common.ErrInternalServerError
+	}
+	return postVersionIDs, nil
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"livoir-blog/internal/domain"
+	"livoir-blog/pkg/common"
+	"livoir-blog/pkg/logger"
+	"livoir-blog/pkg/ulid"
+	"net/http"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+type TagRepository struct {
+	db *sql.DB
+}
+
+func NewTagRepository(db *sql.DB) (domain.TagRepository, error) {
+	if db == nil {
+		return nil, common.NewCustomError(http.StatusInternalServerError, "db is nil")
+	}
+	return &TagRepository{db: db}, nil
+}
+
+func (r *TagRepository) Upsert(ctx context.Context, tx domain.Transaction, tags []domain.Tag, now time.Time) ([]domain.Tag, error) {
+	sqlTx := tx.GetTx()
+	// The no-op update makes RETURNING report tags that already exist
+	query := `INSERT INTO tags (id, name, slug, created_at) VALUES ($1, $2, $3, $4) ON CONFLICT (slug) DO UPDATE SET slug = EXCLUDED.slug RETURNING id, name`
+	upserted := make([]domain.Tag, 0, len(tags))
+	for _, tag := range tags {
+		err := sqlTx.QueryRowContext(ctx, query, ulid.New(), tag.Name, tag.Slug, now).Scan(&tag.ID, &tag.Name)
+		if err != nil {
+			logger.Log.Error("Failed to upsert tag", zap.String("slug", tag.Slug), zap.Error(err))
+			return nil, common.ErrInternalServerError
+		}
+		upserted = append(upserted, tag)
+	}
+	return upserted, nil
+}
+
+func (r *TagRepository) ReplaceOnPostVersion(ctx context.Context, tx domain.Transaction, postVersionID string, tagIDs []string) error {
+	sqlTx := tx.GetTx()
+	_, err := sqlTx.ExecContext(ctx, "DELETE FROM post_version_tags WHERE post_version_id = $1", postVersionID)
+	if err != nil {
+		logger.Log.Error("Failed to detach tags from post version", zap.Error(err))
+		return common.ErrInternalServerError
+	}
+	if len(tagIDs) == 0 {
+		return nil
+	}
+	_, err = sqlTx.ExecContext(ctx, "INSERT INTO post_version_tags (post_version_id, tag_id) SELECT $1, UNNEST($2::VARCHAR[])", postVersionID, pq.StringArray(tagIDs))
+	if err != nil {
+		logger.Log.Error("Failed to attach tags to post version", zap.Error(err))
+		return common.ErrInternalServerError
+	}
+	return nil
+}
+
+func (r *TagRepository) CopyToPostVersion(ctx context.Context, tx domain.Transaction, fromPostVersionID, toPostVersionID string) error {
+	sqlTx := tx.GetTx()
+	_, err := sqlTx.ExecContext(ctx, "DELETE FROM post_version_tags WHERE post_version_id = $1", toPostVersionID)
+	if err != nil {
+		logger.Log.Error("Failed to detach tags from post version", zap.Error(err))
+		return common.ErrInternalServerError
+	}
+	query := `INSERT INTO post_version_tags (post_version_id, tag_id) SELECT $2, tag_id FROM post_version_tags WHERE post_version_id = $1`
+	_, err = sqlTx.ExecContext(ctx, query, fromPostVersionID, toPostVersionID)
+	if err != nil {
+		logger.Log.Error("Failed to copy tags to post version", zap.Error(err))
+		return common.ErrInternalServerError
+	}
+	return nil
+}
+
+func (r *TagRepository) GetByPostVersionID(ctx context.Context, postVersionID string) ([]domain.Tag, error) {
+	query := `SELECT t.id, t.name, t.slug FROM tags t JOIN post_version_tags pvt ON t.id = pvt.tag_id WHERE pvt.post_version_id = $1 ORDER BY t.name`
+	rows, err := r.db.QueryContext(ctx, query, postVersionID)
+	if err != nil {
+		logger.Log.Error("Failed to get tags by post version id", zap.Error(err))
+		return nil, common.ErrInternalServerError
+	}
+	defer rows.Close()
+	tags := []domain.Tag{}
+	for rows.Next() {
+		var tag domain.Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Slug); err != nil {
+			logger.Log.Error("Failed to scan tag", zap.Error(err))
+			return nil, common.ErrInternalServerError
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Log.Error("Failed to iterate tags", zap.Error(err))
+		return nil, common.ErrInternalServerError
+	}
+	return tags, nil
+}
+
+func (r *TagRepository) GetBySlug(ctx context.Context, slug string) (*domain.Tag, error) {
+	var tag domain.Tag
+	err := r.db.QueryRowContext(ctx, "SELECT id, name, slug FROM tags WHERE slug = $1", slug).Scan(&tag.ID, &tag.Name, &tag.Slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, common.ErrTagNotFound
+		}
+		logger.Log.Error("Failed to get tag by slug", zap.Error(err))
+		return nil, common.ErrInternalServerError
+	}
+	return &tag, nil
+}
+
+func (r *TagRepository) ListWithPostCounts(ctx context.Context) ([]domain.TagWithPostCount, error) {
+	// Count the same posts the post listing shows, so drafts do not leak tag names
+	query := `SELECT t.id, t.name, t.slug, COUNT(p.id) FROM tags t JOIN post_version_tags pvt ON t.id = pvt.tag_id JOIN posts p ON p.current_version_id = pvt.post_version_id JOIN post_versions pv ON p.current_version_id = pv.id WHERE pv.published_at IS NOT NULL AND p.unpublished_at IS NULL AND p.archived_at IS NULL AND p.deleted_at IS NULL GROUP BY t.id, t.name, t.slug ORDER BY COUNT(p.id) DESC, t.name`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		logger.Log.Error("Failed to list tags", zap.Error(err))
+		return nil, common.ErrInternalServerError
+	}
+	defer rows.Close()
+	tags := []domain.TagWithPostCount{}
+	for rows.Next() {
+		var tag domain.TagWithPostCount
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Slug, &tag.PostCount); err != nil {
+			logger.Log.Error("Failed to scan tag", zap.Error(err))
+			return nil, common.ErrInternalServerError
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Log.Error("Failed to iterate tags", zap.Error(err))
+		return nil, common.ErrInternalServerError
+	}
+	return tags, nil
+}
@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"livoir-blog/internal/domain"
+	"livoir-blog/pkg/common"
+	"livoir-blog/pkg/ulid"
+	"time"
+)
+
+type autosaveUsecase struct {
+	autosaveRepo domain.AutosaveRepository
+	postRepo     domain.PostRepository
+	adminRepo    domain.AdministratorRepository
+	leaseRepo    domain.EditLeaseRepository
+	postUsecase  domain.PostUsecase
+	limit        int
+	ttl          time.Duration
+}
+
+// NewAutosaveUsecase keeps the newest limit snapshots per post and editor,
+// dropping them once ttl passes without a save.
+func NewAutosaveUsecase(autosaveRepo domain.AutosaveRepository, postRepo domain.PostRepository, adminRepo domain.AdministratorRepository, leaseRepo domain.EditLeaseRepository, postUsecase domain.PostUsecase, limit int, ttl time.Duration) (domain.AutosaveUsecase, error) {
+	if autosaveRepo == nil || postRepo == nil || adminRepo == nil || leaseRepo == nil {
+		return nil, errors.New("nil repository")
+	}
+	if postUsecase == nil {
+		return nil, errors.New("nil post usecase")
+	}
+	if limit <= 0 {
+		return nil, errors.New("autosave limit must be positive")
+	}
+	if ttl <= 0 {
+		return nil, errors.New("autosave ttl must be positive")
+	}
+	return &autosaveUsecase{
+		autosaveRepo: autosaveRepo,
+		postRepo:     postRepo,
+		adminRepo:    adminRepo,
+		leaseRepo:    leaseRepo,
+		postUsecase:  postUsecase,
+		limit:        limit,
+		ttl:          ttl,
+	}, nil
+}
+
+// Save stores a snapshot without touching the draft version. Snapshots keep
+// the editor input as is; it is sanitized when the snapshot is promoted.
+func (u *autosaveUsecase) Save(ctx context.Context, postID string, request *domain.UpdatePostDTO) (*domain.Autosave, error) {
+	admin, err := findEditor(ctx, u.adminRepo, request.EditorEmail)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := u.postRepo.GetDraftByID(ctx, postID); err != nil {
+		return nil, err
+	}
+	lease, err := u.leaseRepo.Get(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if lease != nil && lease.HolderID != admin.ID {
+		return nil, &domain.EditLeaseHeldError{Lease: lease}
+	}
+	autosave := &domain.Autosave{
+		ID:            ulid.New(),
+		PostID:        postID,
+		Title:         request.Title,
+		Content:       request.Content,
+		ContentFormat: request.ContentFormat,
+		Slug:          request.Slug,
+		Excerpt:       request.Excerpt,
+		SEO:           request.SEO,
+		Tags:          request.Tags,
+		SavedAt:       time.Now(),
+	}
+	if err := u.autosaveRepo.Push(ctx, admin.ID, autosave, u.limit, u.ttl); err != nil {
+		return nil, err
+	}
+	return autosave, nil
+}
+
+func (u *autosaveUsecase) List(ctx context.Context, postID, editorEmail string) (*domain.AutosaveListResponseDTO, error) {
+	admin, err := findEditor(ctx, u.adminRepo, editorEmail)
+	if err != nil {
+		return nil, err
+	}
+	autosaves, err := u.autosaveRepo.List(ctx, postID, admin.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.AutosaveListResponseDTO{Autosaves: autosaves}, nil
+}
+
+// Promote writes a snapshot to the draft version through the regular update,
+// so it is sanitized, checked against If-Match and the edit lease, and then
+// clears the editor's snapshots of the post.
+func (u *autosaveUsecase) Promote(ctx context.Context, postID, autosaveID string, request *domain.PromoteAutosaveDTO) (*domain.PostResponseDTO, error) {
+	admin, err := findEditor(ctx, u.adminRepo, request.EditorEmail)
+	if err != nil {
+		return nil, err
+	}
+	autosaves, err := u.autosaveRepo.List(ctx, postID, admin.ID)
+	if err != nil {
+		return nil, err
+	}
+	var autosave *domain.Autosave
+	for i := range autosaves {
+		if autosaves[i].ID == autosaveID {
+			autosave = &autosaves[i]
+			break
+		}
+	}
+	if autosave == nil {
+		return nil, common.ErrAutosaveNotFound
+	}
+	response, err := u.postUsecase.Update(ctx, postID, &domain.UpdatePostDTO{
+		Title:         autosave.Title,
+		Content:       autosave.Content,
+		ContentFormat: autosave.ContentFormat,
+		Slug:          autosave.Slug,
+		Excerpt:       autosave.Excerpt,
+		SEO:           autosave.SEO,
+		Tags:          autosave.Tags,
+		EditorEmail:   request.EditorEmail,
+		IfMatch:       request.IfMatch,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := u.autosaveRepo.Delete(ctx, postID, admin.ID); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (u *autosaveUsecase) Discard(ctx context.Context, postID, editorEmail string) error {
+	admin, err := findEditor(ctx, u.adminRepo, editorEmail)
+	if err != nil {
+		return err
+	}
+	return u.autosaveRepo.Delete(ctx, postID, admin.ID)
+}
@@ -7,6 +7,7 @@ import (
 	"livoir-blog/pkg/common"
 	"livoir-blog/pkg/logger"
 	"net/http"
+	"slices"
 	"time"
 
 	"go.opentelemetry.io/otel"
@@ -33,6 +34,41 @@ func NewCategoryUsecase(transactor domain.Transactor, categoryRepo domain.Catego
 	}, nil
 }
 
+func (u *CategoryUsecase) List(ctx context.Context, request *domain.CategoryListRequestDTO) (*domain.CategoryListResponseDTO, error) {
+	categories, err := u.categoryRepo.ListWithPostCounts(ctx, request.Sort)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.CategoryListResponseDTO{Categories: categories}, nil
+}
+
+func (u *CategoryUsecase) GetByID(ctx context.Context, id string) (*domain.CategoryWithPostCount, error) {
+	return u.categoryRepo.GetWithPostCount(ctx, id)
+}
+
+func (u *CategoryUsecase) GetTree(ctx context.Context) (*domain.CategoryTreeResponseDTO, error) {
+	categories, err := u.categoryRepo.ListWithPostCounts(ctx, domain.CategorySortName)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make(map[string]*domain.CategoryTreeNode, len(categories))
+	for _, category := range categories {
+		nodes[category.ID] = &domain.CategoryTreeNode{CategoryWithPostCount: category, Children: []*domain.CategoryTreeNode{}}
+	}
+	response := &domain.CategoryTreeResponseDTO{Categories: []*domain.CategoryTreeNode{}}
+	// Categories come sorted by name, so every level keeps that order
+	for _, category := range categories {
+		node := nodes[category.ID]
+		parent, ok := nodes[stringValue(category.ParentID)]
+		if !ok {
+			response.Categories = append(response.Categories, node)
+			continue
+		}
+		parent.Children = append(parent.Children, node)
+	}
+	return response, nil
+}
+
 func (u *CategoryUsecase) Create(ctx context.Context, request *domain.CategoryRequestDTO) (*domain.CategoryResponseDTO, error) {
 	tx, err := u.transactor.BeginTx()
 	if err != nil {
@@ -60,9 +96,21 @@ func (u *CategoryUsecase) Create(ctx context.Context, request *domain.CategoryRe
 		err = common.ErrCategoryNameDuplicate
 		return nil, err
 	}
+	var parentID *string
+	if request.ParentID != nil && *request.ParentID != "" {
+		parentID = request.ParentID
+		_, err = u.categoryRepo.GetByIDForUpdate(ctx, tx, *parentID)
+		if errors.Is(err, common.ErrCategoryNotFound) {
+			err = common.ErrParentCategoryNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
 	now := time.Now()
 	category := &domain.Category{
 		Name:      request.Name,
+		ParentID:  parentID,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -77,6 +125,7 @@ func (u *CategoryUsecase) Create(ctx context.Context, request *domain.CategoryRe
 	return &domain.CategoryResponseDTO{
 		ID:        category.ID,
 		Name:      category.Name,
+		ParentID:  category.ParentID,
 		CreatedAt: category.CreatedAt,
 		UpdatedAt: category.UpdatedAt,
 	}, nil
@@ -101,6 +150,12 @@ func (u *CategoryUsecase) Update(ctx context.Context, id string, request *domain
 			}
 		}
 	}(tx)
+	if request.ParentID != nil {
+		err = u.categoryRepo.LockTree(ctx, tx)
+		if err != nil {
+			return nil, err
+		}
+	}
 	existingCategory, err := u.categoryRepo.GetByIDForUpdate(ctx, tx, id)
 	if err != nil {
 		return nil, err
@@ -109,22 +164,49 @@ func (u *CategoryUsecase) Update(ctx context.Context, id string, request *domain
 		err = common.ErrCategoryNotFound
 		return nil, err
 	}
-	if existingCategory.Name == request.Name {
+	parentID := existingCategory.ParentID
+	if request.ParentID != nil {
+		parentID = nil
+		if *request.ParentID != "" {
+			parentID = request.ParentID
+		}
+	}
+	moved := stringValue(parentID) != stringValue(existingCategory.ParentID)
+	if existingCategory.Name == request.Name && !moved {
 		err = common.NewCustomError(http.StatusBadRequest, "name is the same as before")
 		return nil, err
 	}
-	otherCategory, err := u.categoryRepo.GetByName(ctx, request.Name)
-	if err != nil && !errors.Is(err, common.ErrCategoryNotFound) {
-		return nil, err
+	if existingCategory.Name != request.Name {
+		var otherCategory *domain.Category
+		otherCategory, err = u.categoryRepo.GetByName(ctx, request.Name)
+		if err != nil && !errors.Is(err, common.ErrCategoryNotFound) {
+			return nil, err
+		}
+		if otherCategory != nil {
+			err = common.ErrCategoryNameDuplicate
+			return nil, err
+		}
 	}
-	if otherCategory != nil {
-		err = common.ErrCategoryNameDuplicate
-		return nil, err
+	if moved && parentID != nil {
+		// The new parent must not sit below the category itself
+		var ancestorIDs []string
+		ancestorIDs, err = u.categoryRepo.GetAncestorIDs(ctx, tx, *parentID)
+		if errors.Is(err, common.ErrCategoryNotFound) {
+			err = common.ErrParentCategoryNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(ancestorIDs, id) {
+			err = common.ErrCategoryCycle
+			return nil, err
+		}
 	}
 	now := time.Now()
 	category := &domain.Category{
 		ID:        id,
 		Name:      request.Name,
+		ParentID:  parentID,
 		UpdatedAt: now,
 	}
 	err = u.categoryRepo.Update(ctx, tx, category)
@@ -138,11 +220,53 @@ func (u *CategoryUsecase) Update(ctx context.Context, id string, request *domain
 	return &domain.CategoryResponseDTO{
 		ID:        category.ID,
 		Name:      category.Name,
+		ParentID:  category.ParentID,
 		CreatedAt: existingCategory.CreatedAt,
 		UpdatedAt: category.UpdatedAt,
 	}, nil
 }
 
+// Delete removes a category from every post version that carries it. Its
+// subcategories move up to its parent, or to the top level.
+func (u *CategoryUsecase) Delete(ctx context.Context, id string) error {
+	tx, err := u.transactor.BeginTx()
+	if err != nil {
+		return err
+	}
+	defer func(tx domain.Transaction) {
+		if p := recover(); p != nil {
+			e := tx.Rollback()
+			if e != nil {
+				logger.Log.Error("Failed to rollback transaction", zap.Error(e), zap.String("error_source", "panic_recovery"))
+			}
+			panic(p)
+		} else if err != nil {
+			e := tx.Rollback()
+			if e != nil {
+				logger.Log.Error("Failed to rollback transaction", zap.Error(e), zap.String("error_source", "error_propagation"))
+			}
+		}
+	}(tx)
+
+	err = u.categoryRepo.LockTree(ctx, tx)
+	if err != nil {
+		return err
+	}
+	category, err := u.categoryRepo.GetByIDForUpdate(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	err = u.categoryRepo.Delete(ctx, tx, category, time.Now())
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func (u *CategoryUsecase) AttachToPostVersion(ctx context.Context, request *domain.AttachCategoryToPostVersionRequestDTO) error {
 	tx, err := u.transactor.BeginTx()
 	if err != nil {
@@ -196,3 +320,106 @@ func (u *CategoryUsecase) AttachToPostVersion(ctx context.Context, request *doma
 	}
 	return nil
 }
+
+// ReplaceOnPostVersion makes the given categories the only ones of a post
+// version. An empty list removes all of them.
+func (u *CategoryUsecase) ReplaceOnPostVersion(ctx context.Context, request *domain.ReplacePostVersionCategoriesRequestDTO) error {
+	tx, err := u.transactor.BeginTx()
+	if err != nil {
+		return err
+	}
+	defer func(tx domain.Transaction) {
+		if p := recover(); p != nil {
+			e := tx.Rollback()
+			if e != nil {
+				logger.Log.Error("Failed to rollback transaction", zap.Error(e), zap.String("error_source", "panic_recovery"))
+			}
+			panic(p)
+		} else if err != nil {
+			e := tx.Rollback()
+			if e != nil {
+				logger.Log.Error("Failed to rollback transaction", zap.Error(e), zap.String("error_source", "error_propagation"))
+			}
+		}
+	}(tx)
+
+	_, err = u.postVersionRepo.GetByIDForUpdate(ctx, tx, request.PostVersionID)
+	if err != nil {
+		return err
+	}
+	if len(request.CategoryIDs) > 0 {
+		var categories []*domain.Category
+		categories, err = u.categoryRepo.GetByIDs(ctx, request.CategoryIDs)
+		if err != nil {
+			return err
+		}
+		if len(categories) != len(request.CategoryIDs) {
+			err = common.ErrCategoryNotFound
+			return err
+		}
+	}
+	err = u.categoryRepo.DetachAllFromPostVersion(ctx, tx, request.PostVersionID)
+	if err != nil {
+		return err
+	}
+	if len(request.CategoryIDs) > 0 {
+		postVersionCategories := make([]domain.PostVersionCategory, 0, len(request.CategoryIDs))
+		for _, categoryID := range request.CategoryIDs {
+			postVersionCategories = append(postVersionCategories, domain.PostVersionCategory{
+				PostVersionID: request.PostVersionID,
+				CategoryID:    categoryID,
+			})
+		}
+		err = u.categoryRepo.AttachToPostVersion(ctx, tx, postVersionCategories)
+		if err != nil {
+			return err
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (u *CategoryUsecase) DetachFromPostVersion(ctx context.Context, request *domain.DetachCategoryFromPostVersionRequestDTO) error {
+	tx, err := u.transactor.BeginTx()
+	if err != nil {
+		return err
+	}
+	defer func(tx domain.Transaction) {
+		if p := recover(); p != nil {
+			e := tx.Rollback()
+			if e != nil {
+				logger.Log.Error("Failed to rollback transaction", zap.Error(e), zap.String("error_source", "panic_recovery"))
+			}
+			panic(p)
+		} else if err != nil {
+			e := tx.Rollback()
+			if e != nil {
+				logger.Log.Error("Failed to rollback transaction", zap.Error(e), zap.String("error_source", "error_propagation"))
+			}
+		}
+	}(tx)
+
+	_, err = u.postVersionRepo.GetByIDForUpdate(ctx, tx, request.PostVersionID)
+	if err != nil {
+		return err
+	}
+	err = u.categoryRepo.DetachFromPostVersion(ctx, tx, request.PostVersionID, request.CategoryID)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
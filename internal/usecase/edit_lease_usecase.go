@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"livoir-blog/internal/domain"
+	"livoir-blog/pkg/common"
+	"net/http"
+	"slices"
+	"time"
+)
+
+type editLeaseUsecase struct {
+	leaseRepo domain.EditLeaseRepository
+	postRepo  domain.PostRepository
+	adminRepo domain.AdministratorRepository
+	ttl       time.Duration
+}
+
+func NewEditLeaseUsecase(leaseRepo domain.EditLeaseRepository, postRepo domain.PostRepository, adminRepo domain.AdministratorRepository, ttl time.Duration) (domain.EditLeaseUsecase, error) {
+	if leaseRepo == nil || postRepo == nil || adminRepo == nil {
+		return nil, errors.New("nil repository")
+	}
+	if ttl <= 0 {
+		return nil, errors.New("edit lease ttl must be positive")
+	}
+	return &editLeaseUsecase{
+		leaseRepo: leaseRepo,
+		postRepo:  postRepo,
+		adminRepo: adminRepo,
+		ttl:       ttl,
+	}, nil
+}
+
+// Acquire takes the edit lease of a post, or extends it when the editor
+// already holds it. When someone else holds it the error names the holder.
+func (u *editLeaseUsecase) Acquire(ctx context.Context, postID string, request *domain.EditLeaseRequestDTO) (*domain.EditLeaseResponseDTO, error) {
+	admin, err := findEditor(ctx, u.adminRepo, request.EditorEmail)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := u.postRepo.GetDraftByID(ctx, postID); err != nil {
+		return nil, err
+	}
+	acquired, err := u.leaseRepo.Acquire(ctx, &domain.EditLease{
+		PostID:     postID,
+		HolderID:   admin.ID,
+		HolderName: admin.FullName,
+		AcquiredAt: time.Now(),
+	}, u.ttl)
+	if err != nil {
+		return nil, err
+	}
+	lease, err := u.leaseRepo.Get(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if lease == nil {
+		// The lease expired or was released right after it was taken
+		return nil, common.NewCustomError(http.StatusConflict, "edit lease changed, try again")
+	}
+	if !acquired {
+		return nil, &domain.EditLeaseHeldError{Lease: lease}
+	}
+	return &domain.EditLeaseResponseDTO{Acquired: true, Lease: lease}, nil
+}
+
+// Renew is the heartbeat that keeps a lease alive while the editor is active.
+func (u *editLeaseUsecase) Renew(ctx context.Context, postID string, request *domain.EditLeaseRequestDTO) (*domain.EditLeaseResponseDTO, error) {
+	admin, err := findEditor(ctx, u.adminRepo, request.EditorEmail)
+	if err != nil {
+		return nil, err
+	}
+	renewed, err := u.leaseRepo.Renew(ctx, postID, admin.ID, u.ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !renewed {
+		return nil, common.ErrEditLeaseNotHeld
+	}
+	lease, err := u.leaseRepo.Get(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if lease == nil {
+		return nil, common.ErrEditLeaseNotHeld
+	}
+	return &domain.EditLeaseResponseDTO{Acquired: true, Lease: lease}, nil
+}
+
+// Release gives up a lease held by the editor. With Force, an author of the
+// post breaks the lease whoever holds it; posts without authors can be broken
+// by any administrator.
+func (u *editLeaseUsecase) Release(ctx context.Context, postID string, request *domain.EditLeaseRequestDTO) error {
+	admin, err := findEditor(ctx, u.adminRepo, request.EditorEmail)
+	if err != nil {
+		return err
+	}
+	if !request.Force {
+		released, err := u.leaseRepo.Release(ctx, postID, admin.ID)
+		if err != nil {
+			return err
+		}
+		if !released {
+			return common.ErrEditLeaseNotHeld
+		}
+		return nil
+	}
+	post, err := u.postRepo.GetDraftByID(ctx, postID)
+	if err != nil {
+		return err
+	}
+	isAuthor := slices.ContainsFunc(post.Authors, func(author domain.Author) bool {
+		return author.ID == admin.ID
+	})
+	if len(post.Authors) > 0 && !isAuthor {
+		return common.NewCustomError(http.StatusForbidden, "only authors of the post can break its edit lease")
+	}
+	return u.leaseRepo.Break(ctx, postID)
+}
+
+func (u *editLeaseUsecase) Get(ctx context.Context, postID string) (*domain.EditLease, error) {
+	lease, err := u.leaseRepo.Get(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if lease == nil {
+		return nil, common.ErrEditLeaseNotFound
+	}
+	return lease, nil
+}
+
+// findEditor returns the administrator behind the token of an editing request.
+func findEditor(ctx context.Context, adminRepo domain.AdministratorRepository, email string) (*domain.Administrator, error) {
+	admin, err := adminRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, common.ErrUserNotFound) || email == "" {
+			return nil, common.ErrInvalidToken
+		}
+		return nil, err
+	}
+	return admin, nil
+}
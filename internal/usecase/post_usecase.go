@@ -3,10 +3,24 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
+	"html"
 	"livoir-blog/internal/domain"
 	"livoir-blog/pkg/common"
+	"livoir-blog/pkg/diff"
+	"livoir-blog/pkg/etag"
+	"livoir-blog/pkg/highlight"
 	"livoir-blog/pkg/logger"
+	"livoir-blog/pkg/markdown"
+	"livoir-blog/pkg/pagination"
+	"livoir-blog/pkg/sanitize"
+	"livoir-blog/pkg/slug"
+	"livoir-blog/pkg/summary"
 	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/microcosm-cc/bluemonday"
@@ -15,27 +29,93 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	lineDiffContext = 3
+	wordDiffContext = 8
+	maxTags         = 20
+	maxTagLength    = 50
+	// maxTagSlugLength matches tags.slug, which slugs can outgrow as combining
+	// marks do not count towards the slug length limit
+	maxTagSlugLength = 200
+	// draftRevisionLimit bounds the revisions kept per draft for merging
+	draftRevisionLimit = 50
+)
+
 type postUsecase struct {
 	postRepo        domain.PostRepository
 	postVersionRepo domain.PostVersionRepository
+	postSlugRepo    domain.PostSlugRepository
+	categoryRepo    domain.CategoryRepository
+	tagRepo         domain.TagRepository
+	adminRepo       domain.AdministratorRepository
+	leaseRepo       domain.EditLeaseRepository
+	searchRepo      domain.SearchRepository
 	transactor      domain.Transactor
 	sanitizer       *bluemonday.Policy
+	titleSanitizer  *bluemonday.Policy
+	textSanitizer   *bluemonday.Policy
+	highlighter     *highlight.Highlighter
+	excerptLength   int
+	searchLanguage  string
 	tracer          trace.Tracer
 }
 
-func NewPostUsecase(repo domain.PostRepository, postVersionRepo domain.PostVersionRepository, transactor domain.Transactor) (domain.PostUsecase, error) {
-	if repo == nil || postVersionRepo == nil || transactor == nil {
+func NewPostUsecase(repo domain.PostRepository, postVersionRepo domain.PostVersionRepository, postSlugRepo domain.PostSlugRepository, categoryRepo domain.CategoryRepository, tagRepo domain.TagRepository, adminRepo domain.AdministratorRepository, leaseRepo domain.EditLeaseRepository, searchRepo domain.SearchRepository, transactor domain.Transactor, highlighter *highlight.Highlighter, contentPolicy, titlePolicy sanitize.PolicyConfig, excerptLength int, searchLanguage string) (domain.PostUsecase, error) {
+	if repo == nil || postVersionRepo == nil || postSlugRepo == nil || categoryRepo == nil || tagRepo == nil || adminRepo == nil || leaseRepo == nil || searchRepo == nil || transactor == nil {
 		return nil, errors.New("nil repository or transactor")
 	}
+	if highlighter == nil {
+		return nil, errors.New("nil highlighter")
+	}
+	if excerptLength <= 0 {
+		return nil, errors.New("excerpt length must be positive")
+	}
+	if searchLanguage == "" {
+		return nil, errors.New("search language is required")
+	}
+	sanitizer, err := newContentPolicy(contentPolicy)
+	if err != nil {
+		return nil, err
+	}
+	titleSanitizer, err := sanitize.NewPolicy(titlePolicy)
+	if err != nil {
+		return nil, err
+	}
 	return &postUsecase{
 		postRepo:        repo,
 		postVersionRepo: postVersionRepo,
+		postSlugRepo:    postSlugRepo,
+		categoryRepo:    categoryRepo,
+		tagRepo:         tagRepo,
+		adminRepo:       adminRepo,
+		leaseRepo:       leaseRepo,
+		searchRepo:      searchRepo,
 		transactor:      transactor,
-		sanitizer:       bluemonday.UGCPolicy(),
+		sanitizer:       sanitizer,
+		titleSanitizer:  titleSanitizer,
+		textSanitizer:   bluemonday.StrictPolicy(),
+		highlighter:     highlighter,
+		excerptLength:   excerptLength,
+		searchLanguage:  searchLanguage,
 		tracer:          otel.Tracer("post_usecase"),
 	}, nil
 }
 
+// newContentPolicy extends the configured body policy with the markup produced
+// by the Markdown renderer, such as task list checkboxes, and by the highlighter.
+func newContentPolicy(config sanitize.PolicyConfig) (*bluemonday.Policy, error) {
+	policy, err := sanitize.NewPolicy(config)
+	if err != nil {
+		return nil, err
+	}
+	policy.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	policy.AllowAttrs("checked", "disabled").OnElements("input")
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^` + highlight.PreClass + `$`)).OnElements("pre")
+	policy.AllowAttrs("class").Matching(highlight.LanguageClasses()).OnElements("code")
+	policy.AllowAttrs("class").Matching(highlight.TokenClasses()).OnElements("span")
+	return policy, nil
+}
+
 func (u *postUsecase) GetByID(ctx context.Context, id string) (*domain.PostDetailDTO, error) {
 	post, err := u.postRepo.GetByID(ctx, id)
 	if err != nil {
@@ -44,16 +124,474 @@ func (u *postUsecase) GetByID(ctx context.Context, id string) (*domain.PostDetai
 	if post == nil {
 		return nil, common.ErrPostNotFound
 	}
-	postDTO := &domain.PostDetailDTO{
+	return toPostDetailDTO(post), nil
+}
+
+func (u *postUsecase) GetDraftByID(ctx context.Context, id string) (*domain.PostDetailDTO, error) {
+	post, err := u.postRepo.GetDraftByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if post == nil {
+		return nil, common.ErrPostNotFound
+	}
+	return toPostDetailDTO(post), nil
+}
+
+func (u *postUsecase) GetBySlug(ctx context.Context, postSlug string) (*domain.PostDetailDTO, string, error) {
+	post, err := u.postRepo.GetBySlug(ctx, postSlug)
+	if err == nil {
+		return toPostDetailDTO(post), "", nil
+	}
+	if !errors.Is(err, common.ErrPostNotFound) {
+		return nil, "", err
+	}
+	// Old slugs redirect to the slug the post is currently published under
+	currentSlug, err := u.postSlugRepo.GetCurrentSlug(ctx, postSlug)
+	if err != nil {
+		return nil, "", err
+	}
+	return nil, currentSlug, nil
+}
+
+func (u *postUsecase) List(ctx context.Context, request *domain.PostListRequestDTO) (*domain.PostListResponseDTO, error) {
+	filter := &domain.PostListFilter{
+		CategoryID:         request.CategoryID,
+		IncludeDescendants: request.IncludeDescendants,
+		AuthorID:           request.AuthorID,
+		TagID:              request.TagID,
+		PublishedAfter:     request.PublishedAfter,
+		PublishedBefore:    request.PublishedBefore,
+		Limit:              request.Limit + 1,
+	}
+	if request.Cursor != "" {
+		beforeID, err := pagination.DecodeCursor(request.Cursor)
+		if err != nil {
+			return nil, common.NewCustomError(http.StatusBadRequest, "invalid cursor")
+		}
+		filter.BeforeID = beforeID
+	}
+	posts, err := u.postRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return toPostListResponse(posts, request.Limit), nil
+}
+
+func (u *postUsecase) ListDeleted(ctx context.Context, request *domain.PostListRequestDTO) (*domain.PostListResponseDTO, error) {
+	filter := &domain.PostListFilter{
+		Limit: request.Limit + 1,
+	}
+	if request.Cursor != "" {
+		beforeID, err := pagination.DecodeCursor(request.Cursor)
+		if err != nil {
+			return nil, common.NewCustomError(http.StatusBadRequest, "invalid cursor")
+		}
+		filter.BeforeID = beforeID
+	}
+	posts, err := u.postRepo.ListDeleted(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return toPostListResponse(posts, request.Limit), nil
+}
+
+func (u *postUsecase) ListByAuthor(ctx context.Context, authorID string, request *domain.PostListRequestDTO) (*domain.AuthorPostListResponseDTO, error) {
+	admin, err := u.adminRepo.FindByID(ctx, authorID)
+	if err != nil {
+		if errors.Is(err, common.ErrUserNotFound) {
+			return nil, common.ErrAuthorNotFound
+		}
+		return nil, err
+	}
+	request.AuthorID = admin.ID
+	posts, err := u.List(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.AuthorPostListResponseDTO{
+		Author:              domain.Author{ID: admin.ID, DisplayName: admin.FullName},
+		PostListResponseDTO: *posts,
+	}, nil
+}
+
+func (u *postUsecase) ListByTag(ctx context.Context, tagSlug string, request *domain.PostListRequestDTO) (*domain.TagPostListResponseDTO, error) {
+	tag, err := u.tagRepo.GetBySlug(ctx, tagSlug)
+	if err != nil {
+		return nil, err
+	}
+	request.TagID = tag.ID
+	posts, err := u.List(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.TagPostListResponseDTO{
+		Tag:                 *tag,
+		PostListResponseDTO: *posts,
+	}, nil
+}
+
+func (u *postUsecase) ListByCategory(ctx context.Context, categoryID string, request *domain.PostListRequestDTO) (*domain.CategoryPostListResponseDTO, error) {
+	category, err := u.categoryRepo.GetByID(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	request.CategoryID = category.ID
+	posts, err := u.List(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.CategoryPostListResponseDTO{
+		Category:            *category,
+		PostListResponseDTO: *posts,
+	}, nil
+}
+
+// toPostListResponse trims the extra post fetched to detect a next page.
+func toPostListResponse(posts []*domain.PostDetail, limit int) *domain.PostListResponseDTO {
+	response := &domain.PostListResponseDTO{
+		Posts: make([]domain.PostDetail, 0, len(posts)),
+	}
+	if len(posts) > limit {
+		posts = posts[:limit]
+		response.NextCursor = pagination.EncodeCursor(posts[len(posts)-1].ID)
+	}
+	for _, post := range posts {
+		applySEOFallbacks(post)
+		response.Posts = append(response.Posts, *post)
+	}
+	return response
+}
+
+func toPostDetailDTO(post *domain.PostDetail) *domain.PostDetailDTO {
+	// Hash the stored SEO fields, before the fallbacks are filled in
+	postETag := contentETag(post.VersionID, post.Title, post.Slug, post.ContentFormat, post.Source, post.Excerpt, post.SEO)
+	applySEOFallbacks(post)
+	return &domain.PostDetailDTO{
 		PostDetail: *post,
+		JSONLD:     toBlogPosting(post),
+		ETag:       postETag,
+	}
+}
+
+func versionETag(postVersion *domain.PostVersion) string {
+	return contentETag(postVersion.ID, postVersion.Title, postVersion.Slug, postVersion.ContentFormat, postVersion.Source, postVersion.Excerpt, postVersion.SEO)
+}
+
+// contentETag derives the entity tag of a version from its ID and the fields
+// authors edit, so drafts overwritten in place get a new tag too.
+func contentETag(versionID, title, slug, contentFormat, source, excerpt string, seo domain.PostSEO) string {
+	return etag.New(versionID, title, slug, contentFormat, source, excerpt, seo.MetaDescription, seo.CanonicalURL, seo.OGImage, seo.OGTitle, seo.OGDescription, strconv.FormatBool(seo.NoIndex))
+}
+
+// checkEditLease fails while someone other than the editor holds the edit
+// lease of the post, as only the holder may write to it.
+func (u *postUsecase) checkEditLease(ctx context.Context, id string, editorID *string) error {
+	lease, err := u.leaseRepo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if lease != nil && (editorID == nil || *editorID != lease.HolderID) {
+		return &domain.EditLeaseHeldError{Lease: lease}
+	}
+	return nil
+}
+
+// staleVersionError reports a failed precondition together with the latest
+// version of the post, which is what edits and publishes apply to.
+func (u *postUsecase) staleVersionError(ctx context.Context, id string) error {
+	current, err := u.postRepo.GetDraftByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return &domain.StaleVersionError{Current: toPostDetailDTO(current)}
+}
+
+// mergeStaleUpdate rebases an update made on an outdated read onto the current
+// draft with a three-way merge of the title and of the content lines. On a
+// clean merge the request is rewritten to the merged result.
+func (u *postUsecase) mergeStaleUpdate(ctx context.Context, id string, current *domain.PostVersion, request *domain.UpdatePostDTO) error {
+	base, err := u.mergeBase(ctx, id, request.IfMatch)
+	if err != nil {
+		return err
+	}
+	if base == nil {
+		return u.staleVersionError(ctx, id)
+	}
+	if request.ContentFormat == "" {
+		request.ContentFormat = base.ContentFormat
+	}
+	// Sources in different formats cannot be merged line by line
+	if request.ContentFormat != base.ContentFormat || current.ContentFormat != base.ContentFormat {
+		return u.staleVersionError(ctx, id)
+	}
+	_, source, err := u.renderContent(request.ContentFormat, request.Content)
+	if err != nil {
+		return err
+	}
+	var conflicts []domain.MergeConflictDTO
+	title := request.Title
+	switch {
+	case current.Title == base.Title, current.Title == request.Title:
+	case request.Title == base.Title:
+		title = current.Title
+	default:
+		conflicts = append(conflicts, domain.MergeConflictDTO{Field: "title", Base: base.Title, Theirs: current.Title, Ours: request.Title})
+	}
+	content, contentConflicts := diff.Merge3(diff.Lines(base.Source), diff.Lines(current.Source), diff.Lines(source))
+	for _, conflict := range contentConflicts {
+		conflicts = append(conflicts, domain.MergeConflictDTO{
+			Field:     "content",
+			BaseStart: conflict.BaseStart,
+			Base:      strings.Join(conflict.Base, ""),
+			Theirs:    strings.Join(conflict.Theirs, ""),
+			Ours:      strings.Join(conflict.Ours, ""),
+		})
+	}
+	if len(conflicts) > 0 {
+		draft, err := u.postRepo.GetDraftByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		return &domain.MergeConflictError{Current: toPostDetailDTO(draft), Conflicts: conflicts}
+	}
+	request.Title = title
+	request.Content = strings.Join(content, "")
+	return nil
+}
+
+// mergeBase returns the content an If-Match tag was read from, or nil when it
+// is gone. Drafts are edited in place, so older tags of a draft are looked up
+// in the revisions saved before each edit.
+func (u *postUsecase) mergeBase(ctx context.Context, id string, ifMatch string) (*domain.PostVersion, error) {
+	tag := strings.TrimSpace(ifMatch)
+	versionID, ok := etag.ID(tag)
+	if !ok {
+		return nil, nil
+	}
+	base, err := u.postVersionRepo.GetByID(ctx, versionID)
+	if err != nil {
+		if errors.Is(err, common.ErrPostVersionNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if base.PostID != id {
+		return nil, nil
+	}
+	if versionETag(base) == tag {
+		return base, nil
+	}
+	revision, err := u.postVersionRepo.GetRevision(ctx, base.ID, tag)
+	if err != nil {
+		if errors.Is(err, common.ErrPostVersionNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	base.Title = revision.Title
+	base.Slug = revision.Slug
+	base.ContentFormat = revision.ContentFormat
+	base.Source = revision.Source
+	return base, nil
+}
+
+// saveDraftRevision keeps the content of a draft that is about to be edited in
+// place, so edits made from it can still be merged afterwards.
+func (u *postUsecase) saveDraftRevision(ctx context.Context, tx domain.Transaction, draft *domain.PostVersion) error {
+	return u.postVersionRepo.SaveRevision(ctx, tx, &domain.PostVersionRevision{
+		PostVersionID: draft.ID,
+		ETag:          versionETag(draft),
+		Title:         draft.Title,
+		Slug:          draft.Slug,
+		ContentFormat: draft.ContentFormat,
+		Source:        draft.Source,
+		CreatedAt:     time.Now(),
+	}, draftRevisionLimit)
+}
+
+// applySEOFallbacks fills the SEO fields an author left empty from the title
+// and excerpt of the version.
+func applySEOFallbacks(post *domain.PostDetail) {
+	if post.SEO.MetaDescription == "" {
+		post.SEO.MetaDescription = post.Excerpt
+	}
+	if post.SEO.OGTitle == "" {
+		post.SEO.OGTitle = post.Title
+	}
+	if post.SEO.OGDescription == "" {
+		post.SEO.OGDescription = post.SEO.MetaDescription
+	}
+}
+
+// toBlogPosting builds the schema.org JSON-LD document of a post. Titles and
+// excerpts are stored HTML escaped, so they are unescaped for the JSON output.
+func toBlogPosting(post *domain.PostDetail) *domain.BlogPosting {
+	blogPosting := &domain.BlogPosting{
+		Context:          "https://schema.org",
+		Type:             "BlogPosting",
+		Headline:         html.UnescapeString(post.Title),
+		Description:      html.UnescapeString(post.SEO.MetaDescription),
+		Image:            post.SEO.OGImage,
+		URL:              post.SEO.CanonicalURL,
+		MainEntityOfPage: post.SEO.CanonicalURL,
+		DatePublished:    post.PublishedAt,
+		DateModified:     post.UpdatedAt,
+		WordCount:        post.WordCount,
+	}
+	for _, author := range post.Authors {
+		blogPosting.Author = append(blogPosting.Author, domain.Person{Type: "Person", Name: html.UnescapeString(author.DisplayName)})
+	}
+	for _, category := range post.Categories {
+		blogPosting.Keywords = append(blogPosting.Keywords, html.UnescapeString(category.Name))
+	}
+	for _, tag := range post.Tags {
+		blogPosting.Keywords = append(blogPosting.Keywords, html.UnescapeString(tag.Name))
+	}
+	return blogPosting
+}
+
+func (u *postUsecase) ListVersions(ctx context.Context, id string) (*domain.PostVersionListResponseDTO, error) {
+	postVersions, err := u.postVersionRepo.ListByPostID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(postVersions) == 0 {
+		return nil, common.ErrPostNotFound
+	}
+	response := &domain.PostVersionListResponseDTO{
+		Versions: make([]domain.PostVersionSummaryDTO, 0, len(postVersions)),
+	}
+	for _, postVersion := range postVersions {
+		response.Versions = append(response.Versions, domain.PostVersionSummaryDTO{
+			ID:            postVersion.ID,
+			VersionNumber: postVersion.VersionNumber,
+			Title:         postVersion.Title,
+			PublishedAt:   postVersion.PublishedAt,
+			PublishAt:     postVersion.PublishAt,
+			EditorID:      postVersion.EditorID,
+			CreatedAt:     postVersion.CreatedAt,
+		})
+	}
+	return response, nil
+}
+
+func (u *postUsecase) GetVersion(ctx context.Context, id string, versionNumber int64) (*domain.PostVersionDetailDTO, error) {
+	postVersion, err := u.postVersionRepo.GetByPostIDAndVersionNumber(ctx, id, versionNumber)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := u.categoryRepo.GetByPostVersionID(ctx, postVersion.ID)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := u.tagRepo.GetByPostVersionID(ctx, postVersion.ID)
+	if err != nil {
+		return nil, err
+	}
+	response := &domain.PostVersionDetailDTO{
+		PostVersion: *postVersion,
+		Categories:  make([]domain.Category, 0, len(categories)),
+		Tags:        tags,
+	}
+	for _, category := range categories {
+		response.Categories = append(response.Categories, *category)
+	}
+	return response, nil
+}
+
+func (u *postUsecase) DiffVersions(ctx context.Context, id string, request *domain.PostVersionDiffRequestDTO) (*domain.PostVersionDiffDTO, error) {
+	postVersions, err := u.postVersionRepo.ListByPostID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(postVersions) == 0 {
+		return nil, common.ErrPostNotFound
+	}
+	toVersion := request.ToVersion
+	if toVersion == 0 {
+		toVersion = postVersions[0].VersionNumber
+	}
+	fromVersion := request.FromVersion
+	if fromVersion == 0 {
+		// Compare against what readers currently see
+		published, err := u.postRepo.GetByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, common.ErrPostNotFound) {
+				return nil, common.NewCustomError(http.StatusBadRequest, "post has no published version, from version is required")
+			}
+			return nil, err
+		}
+		fromVersion = published.VersionNumber
+	}
+	from, err := u.GetVersion(ctx, id, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	to, err := u.GetVersion(ctx, id, toVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	split, contextSize := diff.Lines, lineDiffContext
+	if request.Granularity == domain.DiffGranularityWord {
+		split, contextSize = diff.Words, wordDiffContext
+	}
+	titleHunks := diff.Hunks(diff.Compute(split(from.Title), split(to.Title)), contextSize)
+	contentHunks := diff.Hunks(diff.Compute(split(from.Source), split(to.Source)), contextSize)
+	response := &domain.PostVersionDiffDTO{
+		PostID:      id,
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+		Granularity: request.Granularity,
+		Title:       toDiffHunkDTOs(titleHunks),
+		Content:     toDiffHunkDTOs(contentHunks),
+		Metadata:    diffMetadata(from, to),
+		Categories:  diffCategories(from.Categories, to.Categories),
+	}
+	if request.Format == domain.DiffFormatUnified {
+		inline := request.Granularity == domain.DiffGranularityWord
+		var b strings.Builder
+		diff.WriteUnified(&b, fmt.Sprintf("title v%d", fromVersion), fmt.Sprintf("title v%d", toVersion), titleHunks, inline)
+		diff.WriteUnified(&b, fmt.Sprintf("content v%d", fromVersion), fmt.Sprintf("content v%d", toVersion), contentHunks, inline)
+		if len(response.Metadata) > 0 {
+			fmt.Fprintf(&b, "--- metadata v%d\n+++ metadata v%d\n", fromVersion, toVersion)
+			for _, change := range response.Metadata {
+				fmt.Fprintf(&b, "-%s: %s\n+%s: %s\n", change.Field, change.From, change.Field, change.To)
+			}
+		}
+		if len(response.Categories.Added) > 0 || len(response.Categories.Removed) > 0 {
+			fmt.Fprintf(&b, "--- categories v%d\n+++ categories v%d\n", fromVersion, toVersion)
+			for _, category := range response.Categories.Removed {
+				b.WriteString("-" + category.Name + "\n")
+			}
+			for _, category := range response.Categories.Added {
+				b.WriteString("+" + category.Name + "\n")
+			}
+		}
+		response.Unified = b.String()
 	}
-	return postDTO, nil
+	return response, nil
 }
 
 func (u *postUsecase) Create(ctx context.Context, request *domain.CreatePostDTO) (*domain.PostResponseDTO, error) {
-	// Sanitize the post content
-	request.Content = u.sanitizer.Sanitize(request.Content)
-	request.Title = u.sanitizer.Sanitize(request.Title)
+	if request.ContentFormat == "" {
+		request.ContentFormat = domain.ContentFormatHTML
+	}
+	// Render and sanitize the post content
+	content, source, err := u.renderContent(request.ContentFormat, request.Content)
+	if err != nil {
+		return nil, err
+	}
+	request.Title = u.titleSanitizer.Sanitize(request.Title)
+	tags, err := u.normalizeTags(request.Tags)
+	if err != nil {
+		return nil, err
+	}
+	editorID, err := u.resolveEditor(ctx, request.EditorEmail)
+	if err != nil {
+		return nil, err
+	}
 	now := time.Now()
 	post := &domain.Post{
 		CreatedAt: now,
@@ -81,6 +619,10 @@ func (u *postUsecase) Create(ctx context.Context, request *domain.CreatePostDTO)
 			}
 		}
 	}(tx)
+	post.Slug, err = u.resolveSlug(ctx, tx, request.Slug, request.Title, "")
+	if err != nil {
+		return nil, err
+	}
 	err = u.postRepo.Create(ctx, tx, post)
 	if err != nil {
 		return nil, err
@@ -90,8 +632,13 @@ func (u *postUsecase) Create(ctx context.Context, request *domain.CreatePostDTO)
 		PostID:        post.ID,
 		CreatedAt:     time.Now(),
 		Title:         request.Title,
-		Content:       request.Content,
+		Slug:          post.Slug,
+		Content:       content,
+		ContentFormat: request.ContentFormat,
+		Source:        source,
+		EditorID:      editorID,
 	}
+	u.summarize(postVersion, request.Excerpt)
 	err = u.postVersionRepo.Create(ctx, tx, postVersion)
 	if err != nil {
 		return nil, err
@@ -100,6 +647,17 @@ func (u *postUsecase) Create(ctx context.Context, request *domain.CreatePostDTO)
 	if err != nil {
 		return nil, err
 	}
+	// Whoever creates a post becomes its first author
+	if editorID != nil {
+		err = u.postRepo.ReplaceAuthors(ctx, tx, post.ID, []domain.PostAuthorDTO{{AdministratorID: *editorID}})
+		if err != nil {
+			return nil, err
+		}
+	}
+	tags, err = u.setVersionTags(ctx, tx, postVersion.ID, tags, now)
+	if err != nil {
+		return nil, err
+	}
 	err = tx.Commit()
 	if err != nil {
 		return nil, err
@@ -108,14 +666,31 @@ func (u *postUsecase) Create(ctx context.Context, request *domain.CreatePostDTO)
 		PostID:        post.ID,
 		Title:         postVersion.Title,
 		Content:       postVersion.Content,
+		ContentFormat: postVersion.ContentFormat,
+		Source:        postVersion.Source,
+		Excerpt:       postVersion.Excerpt,
 		PostVersionID: postVersion.ID,
+		Slug:          postVersion.Slug,
+		Tags:          tags,
+		ETag:          versionETag(postVersion),
 	}, nil
 }
 
 func (u *postUsecase) Update(ctx context.Context, id string, request *domain.UpdatePostDTO) (*domain.PostResponseDTO, error) {
-	// Sanitize the post content
-	request.Content = u.sanitizer.Sanitize(request.Content)
-	request.Title = u.sanitizer.Sanitize(request.Title)
+	request.Title = u.titleSanitizer.Sanitize(request.Title)
+	if request.SEO != nil {
+		if err := u.sanitizeSEO(request.SEO); err != nil {
+			return nil, err
+		}
+	}
+	tags, err := u.normalizeTags(request.Tags)
+	if err != nil {
+		return nil, err
+	}
+	editorID, err := u.resolveEditor(ctx, request.EditorEmail)
+	if err != nil {
+		return nil, err
+	}
 	tx, err := u.transactor.BeginTx()
 	if err != nil {
 		return nil, err
@@ -146,6 +721,10 @@ func (u *postUsecase) Update(ctx context.Context, id string, request *domain.Upd
 	if post == nil {
 		return nil, common.ErrPostNotFound
 	}
+	err = u.checkEditLease(ctx, id, editorID)
+	if err != nil {
+		return nil, err
+	}
 	// Get latest post version
 	postVersion, err := u.postVersionRepo.GetLatestByPostIDForUpdate(ctx, tx, id)
 	if err != nil {
@@ -154,9 +733,51 @@ func (u *postUsecase) Update(ctx context.Context, id string, request *domain.Upd
 	if postVersion == nil {
 		return nil, common.ErrPostVersionNotFound
 	}
+	merged := false
+	if !etag.Match(request.IfMatch, versionETag(postVersion)) {
+		err = u.mergeStaleUpdate(ctx, id, postVersion, request)
+		if err != nil {
+			return nil, err
+		}
+		merged = true
+	}
+	// Keep authoring in the format of the latest version unless told otherwise
+	if request.ContentFormat == "" {
+		request.ContentFormat = postVersion.ContentFormat
+	}
+	content, source, err := u.renderContent(request.ContentFormat, request.Content)
+	if err != nil {
+		return nil, err
+	}
+	versionSlug := postVersion.Slug
+	if request.Slug != "" || (request.Title != "" && request.Title != postVersion.Title) {
+		versionSlug, err = u.resolveSlug(ctx, tx, request.Slug, request.Title, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if request.Tags == nil {
+		tags, err = u.tagRepo.GetByPostVersionID(ctx, postVersion.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	updatedVersion := postVersion
 	if postVersion.PublishedAt == nil {
+		err = u.saveDraftRevision(ctx, tx, postVersion)
+		if err != nil {
+			return nil, err
+		}
 		postVersion.Title = request.Title
-		postVersion.Content = request.Content
+		postVersion.Content = content
+		postVersion.ContentFormat = request.ContentFormat
+		postVersion.Source = source
+		postVersion.Slug = versionSlug
+		postVersion.EditorID = editorID
+		u.summarize(postVersion, request.Excerpt)
+		if request.SEO != nil {
+			postVersion.SEO = *request.SEO
+		}
 		err = u.postVersionRepo.Update(ctx, tx, postVersion)
 		if err != nil {
 			return nil, err
@@ -167,30 +788,55 @@ func (u *postUsecase) Update(ctx context.Context, id string, request *domain.Upd
 			PostID:        id,
 			CreatedAt:     time.Now(),
 			Title:         request.Title,
-			Content:       request.Content,
+			Slug:          versionSlug,
+			Content:       content,
+			ContentFormat: request.ContentFormat,
+			Source:        source,
+			Excerpt:       postVersion.Excerpt,
+			CustomExcerpt: postVersion.CustomExcerpt,
+			SEO:           postVersion.SEO,
+			EditorID:      editorID,
+		}
+		u.summarize(newPostVersion, request.Excerpt)
+		if request.SEO != nil {
+			newPostVersion.SEO = *request.SEO
 		}
+		// The current version keeps pointing at the published one until the new draft is published
 		err = u.postVersionRepo.Create(ctx, tx, newPostVersion)
 		if err != nil {
 			return nil, err
 		}
-		post.CurrentVersionID = newPostVersion.ID
-		err = u.postRepo.Update(ctx, tx, post)
+		err = u.categoryRepo.CopyToPostVersion(ctx, tx, postVersion.ID, newPostVersion.ID)
 		if err != nil {
 			return nil, err
 		}
+		updatedVersion = newPostVersion
+	}
+	tags, err = u.setVersionTags(ctx, tx, updatedVersion.ID, tags, time.Now())
+	if err != nil {
+		return nil, err
 	}
 	err = tx.Commit()
 	if err != nil {
 		return nil, err
 	}
 	return &domain.PostResponseDTO{
-		PostID:  post.ID,
-		Title:   postVersion.Title,
-		Content: postVersion.Content,
+		PostID:        post.ID,
+		PostVersionID: updatedVersion.ID,
+		Slug:          updatedVersion.Slug,
+		Title:         updatedVersion.Title,
+		Content:       updatedVersion.Content,
+		ContentFormat: updatedVersion.ContentFormat,
+		Source:        updatedVersion.Source,
+		Excerpt:       updatedVersion.Excerpt,
+		SEO:           updatedVersion.SEO,
+		Tags:          tags,
+		Merged:        merged,
+		ETag:          versionETag(updatedVersion),
 	}, nil
 }
 
-func (u *postUsecase) Publish(ctx context.Context, id string) (*domain.PublishResponseDTO, error) {
+func (u *postUsecase) Publish(ctx context.Context, id string, request *domain.PublishPostDTO) (*domain.PublishResponseDTO, error) {
 	tx, err := u.transactor.BeginTx()
 	if err != nil {
 		return nil, err
@@ -227,18 +873,21 @@ func (u *postUsecase) Publish(ctx context.Context, id string) (*domain.PublishRe
 	if post == nil {
 		return nil, common.ErrPostNotFound
 	}
-	if postVersion.PublishedAt != nil {
+	if !etag.Match(request.IfMatch, versionETag(postVersion)) {
+		err = u.staleVersionError(ctx, id)
+		return nil, err
+	}
+	if postVersion.PublishedAt != nil && post.UnpublishedAt == nil {
 		return nil, common.NewCustomError(http.StatusForbidden, "post already published")
 	}
-	now := time.Now()
-	postVersion.PublishedAt = &now
-	err = u.postVersionRepo.Update(ctx, tx, postVersion)
-	if err != nil {
-		return nil, err
+	if postVersion.PublishedAt != nil {
+		// The latest version was withdrawn with unpublish, so put it back
+		post.UnpublishedAt = nil
+		post.UpdatedAt = time.Now()
+		err = u.postRepo.Update(ctx, tx, post)
+	} else {
+		err = u.publishVersion(ctx, tx, post, postVersion, time.Now())
 	}
-	post.UpdatedAt = now
-	post.CurrentVersionID = postVersion.ID
-	err = u.postRepo.Update(ctx, tx, post)
 	if err != nil {
 		return nil, err
 	}
@@ -249,9 +898,561 @@ func (u *postUsecase) Publish(ctx context.Context, id string) (*domain.PublishRe
 
 	return &domain.PublishResponseDTO{
 		PostID:      postVersion.PostID,
+		Slug:        post.Slug,
 		PublishedAt: postVersion.PublishedAt,
 		Title:       postVersion.Title,
 		Content:     postVersion.Content,
+		ETag:        versionETag(postVersion),
+	}, nil
+}
+
+func (u *postUsecase) Revert(ctx context.Context, id string, request *domain.RevertPostDTO) (*domain.RevertResponseDTO, error) {
+	editorID, err := u.resolveEditor(ctx, request.EditorEmail)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := u.transactor.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+	defer func(tx domain.Transaction) {
+		if p := recover(); p != nil {
+			e := tx.Rollback()
+			if e != nil {
+				logger.Log.Error("Failed to rollback transaction",
+					zap.Error(e),
+					zap.String("error_source", "panic_recovery"))
+			}
+			panic(p)
+		} else if err != nil {
+			e := tx.Rollback()
+			if e != nil {
+				logger.Log.Error("Failed to rollback transaction",
+					zap.Error(e),
+					zap.String("error_source", "error_handling"))
+			}
+		}
+	}(tx)
+	post, err := u.postRepo.GetByIDForUpdate(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	err = u.checkEditLease(ctx, id, editorID)
+	if err != nil {
+		return nil, err
+	}
+	latestVersion, err := u.postVersionRepo.GetLatestByPostIDForUpdate(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !etag.Match(request.IfMatch, versionETag(latestVersion)) {
+		err = u.staleVersionError(ctx, id)
+		return nil, err
+	}
+	if latestVersion.VersionNumber == request.VersionNumber {
+		err = common.NewCustomError(http.StatusBadRequest, "version is already the latest version")
+		return nil, err
+	}
+	targetVersion, err := u.postVersionRepo.GetByPostIDAndVersionNumberForUpdate(ctx, tx, id, request.VersionNumber)
+	if err != nil {
+		return nil, err
+	}
+	// Fall back to the current slug when another post took the target's slug
+	// after the target version was written
+	versionSlug := targetVersion.Slug
+	taken, err := u.postSlugRepo.IsTaken(ctx, tx, versionSlug, id)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		versionSlug = latestVersion.Slug
+	}
+	// Same rules as Update: overwrite an unpublished draft, otherwise start a new version
+	draft := latestVersion
+	if latestVersion.PublishedAt == nil {
+		err = u.saveDraftRevision(ctx, tx, draft)
+		if err != nil {
+			return nil, err
+		}
+		draft.Title = targetVersion.Title
+		draft.Content = targetVersion.Content
+		draft.ContentFormat = targetVersion.ContentFormat
+		draft.Source = targetVersion.Source
+		draft.Excerpt = targetVersion.Excerpt
+		draft.CustomExcerpt = targetVersion.CustomExcerpt
+		draft.WordCount = targetVersion.WordCount
+		draft.ReadingTimeMinutes = targetVersion.ReadingTimeMinutes
+		draft.SEO = targetVersion.SEO
+		draft.EditorID = editorID
+		draft.Slug = versionSlug
+		err = u.postVersionRepo.Update(ctx, tx, draft)
+		if err != nil {
+			return nil, err
+		}
+		err = u.categoryRepo.DetachAllFromPostVersion(ctx, tx, draft.ID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		draft = &domain.PostVersion{
+			VersionNumber:      latestVersion.VersionNumber + 1,
+			PostID:             id,
+			CreatedAt:          time.Now(),
+			Title:              targetVersion.Title,
+			Slug:               versionSlug,
+			Content:            targetVersion.Content,
+			ContentFormat:      targetVersion.ContentFormat,
+			Source:             targetVersion.Source,
+			Excerpt:            targetVersion.Excerpt,
+			CustomExcerpt:      targetVersion.CustomExcerpt,
+			WordCount:          targetVersion.WordCount,
+			ReadingTimeMinutes: targetVersion.ReadingTimeMinutes,
+			SEO:                targetVersion.SEO,
+			EditorID:           editorID,
+		}
+		err = u.postVersionRepo.Create(ctx, tx, draft)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = u.categoryRepo.CopyToPostVersion(ctx, tx, targetVersion.ID, draft.ID)
+	if err != nil {
+		return nil, err
+	}
+	err = u.tagRepo.CopyToPostVersion(ctx, tx, targetVersion.ID, draft.ID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Publish {
+		err = u.publishVersion(ctx, tx, post, draft, time.Now())
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return &domain.RevertResponseDTO{
+		PostID:        id,
+		PostVersionID: draft.ID,
+		VersionNumber: draft.VersionNumber,
+		RevertedFrom:  targetVersion.VersionNumber,
+		Slug:          draft.Slug,
+		Title:         draft.Title,
+		Content:       draft.Content,
+		PublishedAt:   draft.PublishedAt,
+		ETag:          versionETag(draft),
+	}, nil
+}
+
+func (u *postUsecase) Schedule(ctx context.Context, id string, request *domain.SchedulePostDTO) (*domain.ScheduleResponseDTO, error) {
+	if !request.PublishAt.After(time.Now()) {
+		return nil, common.NewCustomError(http.StatusBadRequest, "publish_at must be in the future")
+	}
+	publishAt := request.PublishAt
+	return u.setPublishAt(ctx, id, &publishAt)
+}
+
+func (u *postUsecase) CancelSchedule(ctx context.Context, id string) (*domain.ScheduleResponseDTO, error) {
+	return u.setPublishAt(ctx, id, nil)
+}
+
+func (u *postUsecase) setPublishAt(ctx context.Context, id string, publishAt *time.Time) (*domain.ScheduleResponseDTO, error) {
+	tx, err := u.transactor.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+	defer func(tx domain.Transaction) {
+		if p := recover(); p != nil {
+			e := tx.Rollback()
+			if e != nil {
+				logger.Log.Error("Failed to rollback transaction",
+					zap.Error(e),
+					zap.String("error_source", "panic_recovery"))
+			}
+			panic(p)
+		} else if err != nil {
+			e := tx.Rollback()
+			if e != nil {
+				logger.Log.Error("Failed to rollback transaction",
+					zap.Error(e),
+					zap.String("error_source", "error_handling"))
+			}
+		}
+	}(tx)
+	postVersion, err := u.postVersionRepo.GetLatestByPostIDForUpdate(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if postVersion.PublishedAt != nil {
+		err = common.NewCustomError(http.StatusConflict, "post has no unpublished draft to schedule")
+		return nil, err
+	}
+	if publishAt == nil && postVersion.PublishAt == nil {
+		err = common.NewCustomError(http.StatusNotFound, "post is not scheduled")
+		return nil, err
+	}
+	postVersion.PublishAt = publishAt
+	err = u.postVersionRepo.Update(ctx, tx, postVersion)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return &domain.ScheduleResponseDTO{
+		PostID:        id,
+		PostVersionID: postVersion.ID,
+		VersionNumber: postVersion.VersionNumber,
+		PublishAt:     postVersion.PublishAt,
+	}, nil
+}
+
+// PublishScheduled publishes every draft whose publish_at has passed and
+// returns how many were published. Each draft is published in its own
+// transaction. A draft that fails is logged and skipped for the rest of the
+// run, so it does not hold back the drafts due after it.
+func (u *postUsecase) PublishScheduled(ctx context.Context) (int, error) {
+	published := 0
+	var skipped []string
+	for ctx.Err() == nil {
+		postVersion, err := u.postVersionRepo.GetNextDue(ctx, time.Now(), skipped)
+		if err != nil {
+			return published, err
+		}
+		if postVersion == nil {
+			break
+		}
+		ok, err := u.publishScheduledVersion(ctx, postVersion)
+		if err != nil {
+			if ctx.Err() != nil {
+				return published, err
+			}
+			logger.Log.Error("Failed to publish scheduled post",
+				zap.String("postID", postVersion.PostID),
+				zap.String("postVersionID", postVersion.ID),
+				zap.Error(err))
+		}
+		if !ok {
+			skipped = append(skipped, postVersion.ID)
+			continue
+		}
+		published++
+	}
+	return published, nil
+}
+
+// publishScheduledVersion publishes due if it is still due once locked. The
+// post is locked before the version, in the same order as Update.
+func (u *postUsecase) publishScheduledVersion(ctx context.Context, due *domain.PostVersion) (bool, error) {
+	tx, err := u.transactor.BeginTx()
+	if err != nil {
+		return false, err
+	}
+	defer func(tx domain.Transaction) {
+		if p := recover(); p != nil {
+			e := tx.Rollback()
+			if e != nil {
+				logger.Log.Error("Failed to rollback transaction",
+					zap.Error(e),
+					zap.String("error_source", "panic_recovery"))
+			}
+			panic(p)
+		} else if err != nil {
+			e := tx.Rollback()
+			if e != nil {
+				logger.Log.Error("Failed to rollback transaction",
+					zap.Error(e),
+					zap.String("error_source", "error_handling"))
+			}
+		}
+	}(tx)
+	post, err := u.postRepo.GetByIDForUpdate(ctx, tx, due.PostID)
+	if err != nil {
+		return false, err
+	}
+	postVersion, err := u.postVersionRepo.GetByIDForUpdate(ctx, tx, due.ID)
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	if postVersion.PublishedAt != nil || postVersion.PublishAt == nil || postVersion.PublishAt.After(now) {
+		// Published by another replica or rescheduled in the meantime
+		err = tx.Commit()
+		return false, err
+	}
+	err = u.publishVersion(ctx, tx, post, postVersion, now)
+	if err != nil {
+		return false, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+	logger.Log.Info("Published scheduled post",
+		zap.String("postID", post.ID),
+		zap.Int64("versionNumber", postVersion.VersionNumber))
+	return true, nil
+}
+
+// ReindexSearch rebuilds the search documents of searchable posts that are
+// missing or were built with another language than the configured one, such as
+// those backfilled by the migration. It returns how many were rebuilt.
+func (u *postUsecase) ReindexSearch(ctx context.Context) (int, error) {
+	postVersionIDs, err := u.searchRepo.ListStale(ctx, u.searchLanguage)
+	if err != nil {
+		return 0, err
+	}
+	if len(postVersionIDs) == 0 {
+		return 0, nil
+	}
+	tx, err := u.transactor.BeginTx()
+	if err != nil {
+		return 0, err
+	}
+	defer func(tx domain.Transaction) {
+		if p := recover(); p != nil {
+			e := tx.Rollback()
+			if e != nil {
+				logger.Log.Error("Failed to rollback transaction",
+					zap.Error(e),
+					zap.String("error_source", "panic_recovery"))
+			}
+			panic(p)
+		} else if err != nil {
+			e := tx.Rollback()
+			if e != nil {
+				logger.Log.Error("Failed to rollback transaction",
+					zap.Error(e),
+					zap.String("error_source", "error_handling"))
+			}
+		}
+	}(tx)
+	now := time.Now()
+	for _, postVersionID := range postVersionIDs {
+		var postVersion *domain.PostVersion
+		postVersion, err = u.postVersionRepo.GetByID(ctx, postVersionID)
+		if err != nil {
+			return 0, err
+		}
+		err = u.indexVersion(ctx, tx, postVersion, now)
+		if err != nil {
+			return 0, err
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+	return len(postVersionIDs), nil
+}
+
+// Unpublish withdraws a published post from public reads. Its versions are
+// kept, and publishing again makes it public.
+func (u *postUsecase) Unpublish(ctx context.Context, id string) (*domain.PostStatusResponseDTO, error) {
+	return u.changePostStatus(ctx, id, u.postRepo.GetByIDForUpdate, func(post *domain.Post, now time.Time) error {
+		if post.CurrentVersionID == "" || post.UnpublishedAt != nil {
+			return common.NewCustomError(http.StatusConflict, "post is not published")
+		}
+		post.UnpublishedAt = &now
+		return nil
+	})
+}
+
+// Archive hides a published post from listings while its permalink keeps
+// working.
+func (u *postUsecase) Archive(ctx context.Context, id string) (*domain.PostStatusResponseDTO, error) {
+	return u.changePostStatus(ctx, id, u.postRepo.GetByIDForUpdate, func(post *domain.Post, now time.Time) error {
+		if post.CurrentVersionID == "" || post.UnpublishedAt != nil {
+			return common.NewCustomError(http.StatusConflict, "post is not published")
+		}
+		if post.ArchivedAt != nil {
+			return common.NewCustomError(http.StatusConflict, "post is already archived")
+		}
+		post.ArchivedAt = &now
+		return nil
+	})
+}
+
+func (u *postUsecase) Unarchive(ctx context.Context, id string) (*domain.PostStatusResponseDTO, error) {
+	return u.changePostStatus(ctx, id, u.postRepo.GetByIDForUpdate, func(post *domain.Post, now time.Time) error {
+		if post.ArchivedAt == nil {
+			return common.NewCustomError(http.StatusConflict, "post is not archived")
+		}
+		post.ArchivedAt = nil
+		return nil
+	})
+}
+
+// Delete moves a post to the trash. It disappears from every read until it is
+// restored or purged.
+func (u *postUsecase) Delete(ctx context.Context, id string) (*domain.PostStatusResponseDTO, error) {
+	return u.changePostStatus(ctx, id, u.postRepo.GetByIDForUpdate, func(post *domain.Post, now time.Time) error {
+		post.DeletedAt = &now
+		return nil
+	})
+}
+
+func (u *postUsecase) Restore(ctx context.Context, id string) (*domain.PostStatusResponseDTO, error) {
+	return u.changePostStatus(ctx, id, u.postRepo.GetDeletedByIDForUpdate, func(post *domain.Post, now time.Time) error {
+		post.DeletedAt = nil
+		return nil
+	})
+}
+
+// PurgeDeleted permanently removes posts that were moved to the trash before
+// the given time and returns how many were removed.
+func (u *postUsecase) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := u.transactor.BeginTx()
+	if err != nil {
+		return 0, err
+	}
+	defer func(tx domain.Transaction) {
+		if p := recover(); p != nil {
+			e := tx.Rollback()
+			if e != nil {
+				logger.Log.Error("Failed to rollback transaction",
+					zap.Error(e),
+					zap.String("error_source", "panic_recovery"))
+			}
+			panic(p)
+		} else if err != nil {
+			e := tx.Rollback()
+			if e != nil {
+				logger.Log.Error("Failed to rollback transaction",
+					zap.Error(e),
+					zap.String("error_source", "error_handling"))
+			}
+		}
+	}(tx)
+	purged, err := u.postRepo.PurgeDeleted(ctx, tx, before)
+	if err != nil {
+		return 0, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
+
+// SetAuthors replaces the authors of a post, keeping the order of the request.
+// Display names are optional and fall back to the administrator's full name.
+func (u *postUsecase) SetAuthors(ctx context.Context, id string, request *domain.SetPostAuthorsDTO) (*domain.PostAuthorsResponseDTO, error) {
+	if len(request.Authors) == 0 {
+		return nil, common.NewCustomError(http.StatusBadRequest, "at least one author is required")
+	}
+	response := &domain.PostAuthorsResponseDTO{
+		PostID:  id,
+		Authors: make([]domain.Author, 0, len(request.Authors)),
+	}
+	seen := make(map[string]bool, len(request.Authors))
+	for i := range request.Authors {
+		author := &request.Authors[i]
+		if seen[author.AdministratorID] {
+			return nil, common.NewCustomError(http.StatusBadRequest, "authors must not repeat")
+		}
+		seen[author.AdministratorID] = true
+		admin, err := u.adminRepo.FindByID(ctx, author.AdministratorID)
+		if err != nil {
+			if errors.Is(err, common.ErrUserNotFound) {
+				return nil, common.NewCustomError(http.StatusBadRequest, fmt.Sprintf("administrator %s does not exist", author.AdministratorID))
+			}
+			return nil, err
+		}
+		author.DisplayName = u.textSanitizer.Sanitize(strings.TrimSpace(author.DisplayName))
+		displayName := author.DisplayName
+		if displayName == "" {
+			displayName = admin.FullName
+		}
+		response.Authors = append(response.Authors, domain.Author{ID: admin.ID, DisplayName: displayName})
+	}
+	tx, err := u.transactor.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+	defer func(tx domain.Transaction) {
+		if p := recover(); p != nil {
+			e := tx.Rollback()
+			if e != nil {
+				logger.Log.Error("Failed to rollback transaction",
+					zap.Error(e),
+					zap.String("error_source", "panic_recovery"))
+			}
+			panic(p)
+		} else if err != nil {
+			e := tx.Rollback()
+			if e != nil {
+				logger.Log.Error("Failed to rollback transaction",
+					zap.Error(e),
+					zap.String("error_source", "error_handling"))
+			}
+		}
+	}(tx)
+	_, err = u.postRepo.GetByIDForUpdate(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	err = u.postRepo.ReplaceAuthors(ctx, tx, id, request.Authors)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (u *postUsecase) changePostStatus(ctx context.Context, id string, lock func(ctx context.Context, tx domain.Transaction, id string) (*domain.Post, error), change func(post *domain.Post, now time.Time) error) (*domain.PostStatusResponseDTO, error) {
+	tx, err := u.transactor.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+	defer func(tx domain.Transaction) {
+		if p := recover(); p != nil {
+			e := tx.Rollback()
+			if e != nil {
+				logger.Log.Error("Failed to rollback transaction",
+					zap.Error(e),
+					zap.String("error_source", "panic_recovery"))
+			}
+			panic(p)
+		} else if err != nil {
+			e := tx.Rollback()
+			if e != nil {
+				logger.Log.Error("Failed to rollback transaction",
+					zap.Error(e),
+					zap.String("error_source", "error_handling"))
+			}
+		}
+	}(tx)
+	post, err := lock(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	err = change(post, now)
+	if err != nil {
+		return nil, err
+	}
+	post.UpdatedAt = now
+	err = u.postRepo.Update(ctx, tx, post)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return &domain.PostStatusResponseDTO{
+		PostID:        post.ID,
+		Slug:          post.Slug,
+		UnpublishedAt: post.UnpublishedAt,
+		ArchivedAt:    post.ArchivedAt,
+		DeletedAt:     post.DeletedAt,
 	}, nil
 }
 
@@ -289,6 +1490,11 @@ func (u *postUsecase) DeletePostVersionByPostID(ctx context.Context, id string)
 	if postVersion.PublishedAt != nil {
 		return common.NewCustomError(http.StatusConflict, "post version is published")
 	}
+	// Drafts carry over the categories of the version they were made from
+	err = u.categoryRepo.DetachAllFromPostVersion(ctx, tx, postVersion.ID)
+	if err != nil {
+		return err
+	}
 	err = u.postVersionRepo.Delete(ctx, tx, postVersion.ID)
 	if err != nil {
 		return err
@@ -299,3 +1505,302 @@ func (u *postUsecase) DeletePostVersionByPostID(ctx context.Context, id string)
 	}
 	return nil
 }
+
+// publishVersion marks postVersion as published and makes it the current
+// version of post. Both rows must already be locked by the caller's transaction.
+func (u *postUsecase) publishVersion(ctx context.Context, tx domain.Transaction, post *domain.Post, postVersion *domain.PostVersion, now time.Time) error {
+	postVersion.PublishedAt = &now
+	postVersion.PublishAt = nil
+	err := u.postVersionRepo.Update(ctx, tx, postVersion)
+	if err != nil {
+		return err
+	}
+	if post.Slug != postVersion.Slug {
+		// Keep the slug readers already know so it can redirect to the new one
+		if post.CurrentVersionID != "" {
+			err = u.postSlugRepo.Create(ctx, tx, &domain.PostSlug{
+				Slug:      post.Slug,
+				PostID:    post.ID,
+				CreatedAt: now,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		err = u.postSlugRepo.Delete(ctx, tx, post.ID, postVersion.Slug)
+		if err != nil {
+			return err
+		}
+		post.Slug = postVersion.Slug
+	}
+	post.UpdatedAt = now
+	post.UnpublishedAt = nil
+	post.CurrentVersionID = postVersion.ID
+	err = u.postRepo.Update(ctx, tx, post)
+	if err != nil {
+		return err
+	}
+	return u.indexVersion(ctx, tx, postVersion, now)
+}
+
+// indexVersion refreshes the search document of a post from its current
+// version.
+func (u *postUsecase) indexVersion(ctx context.Context, tx domain.Transaction, postVersion *domain.PostVersion, now time.Time) error {
+	markers := strings.NewReplacer(domain.SnippetStartSel, "", domain.SnippetStopSel, "")
+	return u.searchRepo.Index(ctx, tx, &domain.SearchDocument{
+		PostID:        postVersion.PostID,
+		PostVersionID: postVersion.ID,
+		Language:      u.searchLanguage,
+		Title:         markers.Replace(summary.PlainText(postVersion.Title)),
+		Body:          markers.Replace(summary.PlainText(postVersion.Content)),
+		UpdatedAt:     now,
+	})
+}
+
+// renderContent turns submitted content into the highlighted, sanitized HTML
+// served to readers and the source kept for editing. Markdown source is kept as
+// written, while HTML source keeps only the sanitized markup.
+func (u *postUsecase) renderContent(format, content string) (string, string, error) {
+	var rendered, source string
+	switch format {
+	case domain.ContentFormatHTML:
+		source = u.sanitizer.Sanitize(content)
+		rendered = content
+	case domain.ContentFormatMarkdown:
+		var err error
+		source = content
+		rendered, err = markdown.Render(content)
+		if err != nil {
+			logger.Log.Error("Failed to render markdown", zap.Error(err))
+			return "", "", common.NewCustomError(http.StatusBadRequest, "failed to render markdown content")
+		}
+	default:
+		return "", "", common.NewCustomError(http.StatusBadRequest, "content_format must be html or markdown")
+	}
+	// Highlight before sanitizing so the language classes are still there
+	highlighted, err := u.highlighter.HTML(rendered)
+	if err != nil {
+		logger.Log.Error("Failed to highlight code blocks", zap.Error(err))
+		highlighted = rendered
+	}
+	return u.sanitizer.Sanitize(highlighted), source, nil
+}
+
+// summarize computes the word count, reading time and excerpt of a version from
+// its content. A non-empty excerpt is kept as a custom excerpt across later
+// edits, while an empty one goes back to the computed excerpt.
+func (u *postUsecase) summarize(postVersion *domain.PostVersion, excerpt *string) {
+	text := summary.PlainText(postVersion.Content)
+	postVersion.WordCount = summary.WordCount(text)
+	postVersion.ReadingTimeMinutes = summary.ReadingTime(postVersion.WordCount)
+	if excerpt != nil {
+		postVersion.CustomExcerpt = strings.TrimSpace(*excerpt) != ""
+		if postVersion.CustomExcerpt {
+			postVersion.Excerpt = u.textSanitizer.Sanitize(*excerpt)
+			return
+		}
+	}
+	if !postVersion.CustomExcerpt {
+		// Stored escaped like titles, which go through the sanitizer
+		postVersion.Excerpt = html.EscapeString(summary.Excerpt(text, u.excerptLength))
+	}
+}
+
+// resolveEditor returns the administrator ID behind the email of an
+// authenticated request, or nil when the request was not authenticated.
+func (u *postUsecase) resolveEditor(ctx context.Context, email string) (*string, error) {
+	if email == "" {
+		return nil, nil
+	}
+	admin, err := u.adminRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, common.ErrUserNotFound) {
+			return nil, common.ErrInvalidToken
+		}
+		return nil, err
+	}
+	return &admin.ID, nil
+}
+
+// sanitizeSEO strips markup from the SEO text fields and requires the URLs to
+// be absolute http or https URLs.
+func (u *postUsecase) sanitizeSEO(seo *domain.PostSEO) error {
+	seo.MetaDescription = u.textSanitizer.Sanitize(strings.TrimSpace(seo.MetaDescription))
+	seo.OGTitle = u.textSanitizer.Sanitize(strings.TrimSpace(seo.OGTitle))
+	seo.OGDescription = u.textSanitizer.Sanitize(strings.TrimSpace(seo.OGDescription))
+	seo.CanonicalURL = strings.TrimSpace(seo.CanonicalURL)
+	seo.OGImage = strings.TrimSpace(seo.OGImage)
+	for _, rawURL := range []string{seo.CanonicalURL, seo.OGImage} {
+		if rawURL == "" {
+			continue
+		}
+		parsed, err := url.Parse(rawURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return common.NewCustomError(http.StatusBadRequest, "seo urls must be absolute http or https urls")
+		}
+	}
+	return nil
+}
+
+// resolveSlug returns the slug for a post version. An explicit slug must not be
+// used by another post, while a slug generated from the title gets a numeric
+// suffix until it is unique.
+func (u *postUsecase) resolveSlug(ctx context.Context, tx domain.Transaction, explicitSlug, title, postID string) (string, error) {
+	if explicitSlug != "" {
+		postSlug := slug.Make(explicitSlug)
+		if postSlug == "" {
+			return "", common.NewCustomError(http.StatusBadRequest, "invalid slug")
+		}
+		taken, err := u.postSlugRepo.IsTaken(ctx, tx, postSlug, postID)
+		if err != nil {
+			return "", err
+		}
+		if taken {
+			return "", common.ErrPostSlugDuplicate
+		}
+		return postSlug, nil
+	}
+	base := slug.Make(html.UnescapeString(u.textSanitizer.Sanitize(title)))
+	if base == "" {
+		base = "post"
+	}
+	candidate := base
+	for i := 2; ; i++ {
+		taken, err := u.postSlugRepo.IsTaken(ctx, tx, candidate, postID)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+func toDiffHunkDTOs(hunks []diff.Hunk) []domain.DiffHunkDTO {
+	hunkDTOs := make([]domain.DiffHunkDTO, 0, len(hunks))
+	for _, hunk := range hunks {
+		changes := make([]domain.DiffChangeDTO, 0, len(hunk.Changes))
+		for _, change := range hunk.Changes {
+			changes = append(changes, domain.DiffChangeDTO{
+				Type: string(change.Op),
+				Text: change.Text,
+			})
+		}
+		hunkDTOs = append(hunkDTOs, domain.DiffHunkDTO{
+			FromStart: hunk.FromStart,
+			FromCount: hunk.FromCount,
+			ToStart:   hunk.ToStart,
+			ToCount:   hunk.ToCount,
+			Changes:   changes,
+		})
+	}
+	return hunkDTOs
+}
+
+func diffCategories(from, to []domain.Category) domain.CategoryDiffDTO {
+	categoryDiff := domain.CategoryDiffDTO{
+		Added:   []domain.Category{},
+		Removed: []domain.Category{},
+	}
+	fromIDs := make(map[string]bool, len(from))
+	for _, category := range from {
+		fromIDs[category.ID] = true
+	}
+	toIDs := make(map[string]bool, len(to))
+	for _, category := range to {
+		toIDs[category.ID] = true
+		if !fromIDs[category.ID] {
+			categoryDiff.Added = append(categoryDiff.Added, category)
+		}
+	}
+	for _, category := range from {
+		if !toIDs[category.ID] {
+			categoryDiff.Removed = append(categoryDiff.Removed, category)
+		}
+	}
+	return categoryDiff
+}
+
+// diffMetadata lists the metadata fields whose values differ between two
+// versions, in a fixed order.
+func diffMetadata(from, to *domain.PostVersionDetailDTO) []domain.FieldChangeDTO {
+	tagNames := func(tags []domain.Tag) string {
+		names := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			names = append(names, tag.Name)
+		}
+		return strings.Join(names, ", ")
+	}
+	fields := []struct {
+		name     string
+		from, to string
+	}{
+		{"slug", from.Slug, to.Slug},
+		{"content_format", from.ContentFormat, to.ContentFormat},
+		{"excerpt", from.Excerpt, to.Excerpt},
+		{"tags", tagNames(from.Tags), tagNames(to.Tags)},
+		{"meta_description", from.SEO.MetaDescription, to.SEO.MetaDescription},
+		{"canonical_url", from.SEO.CanonicalURL, to.SEO.CanonicalURL},
+		{"og_image", from.SEO.OGImage, to.SEO.OGImage},
+		{"og_title", from.SEO.OGTitle, to.SEO.OGTitle},
+		{"og_description", from.SEO.OGDescription, to.SEO.OGDescription},
+		{"noindex", strconv.FormatBool(from.SEO.NoIndex), strconv.FormatBool(to.SEO.NoIndex)},
+	}
+	changes := []domain.FieldChangeDTO{}
+	for _, field := range fields {
+		if field.from != field.to {
+			changes = append(changes, domain.FieldChangeDTO{Field: field.name, From: field.from, To: field.to})
+		}
+	}
+	return changes
+}
+
+// normalizeTags lowercases tag names, collapses their whitespace and drops
+// names that repeat under the same slug.
+func (u *postUsecase) normalizeTags(names []string) ([]domain.Tag, error) {
+	tags := make([]domain.Tag, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.ToLower(summary.PlainText(name))
+		tagSlug := slug.Make(name)
+		if tagSlug == "" {
+			return nil, common.NewCustomError(http.StatusBadRequest, fmt.Sprintf("invalid tag %q", name))
+		}
+		// The length limit applies to the stored, escaped name
+		sanitized := u.textSanitizer.Sanitize(name)
+		if len([]rune(sanitized)) > maxTagLength {
+			return nil, common.NewCustomError(http.StatusBadRequest, fmt.Sprintf("tags must be at most %d characters", maxTagLength))
+		}
+		if len([]rune(tagSlug)) > maxTagSlugLength {
+			return nil, common.NewCustomError(http.StatusBadRequest, fmt.Sprintf("tag slugs must be at most %d characters", maxTagSlugLength))
+		}
+		if seen[tagSlug] {
+			continue
+		}
+		seen[tagSlug] = true
+		tags = append(tags, domain.Tag{Name: sanitized, Slug: tagSlug})
+	}
+	if len(tags) > maxTags {
+		return nil, common.NewCustomError(http.StatusBadRequest, fmt.Sprintf("a post can have at most %d tags", maxTags))
+	}
+	return tags, nil
+}
+
+// setVersionTags creates the missing tags and makes them the tags of a post
+// version.
+func (u *postUsecase) setVersionTags(ctx context.Context, tx domain.Transaction, postVersionID string, tags []domain.Tag, now time.Time) ([]domain.Tag, error) {
+	tags, err := u.tagRepo.Upsert(ctx, tx, tags, now)
+	if err != nil {
+		return nil, err
+	}
+	tagIDs := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	err = u.tagRepo.ReplaceOnPostVersion(ctx, tx, postVersionID, tagIDs)
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"livoir-blog/internal/domain"
+	"livoir-blog/pkg/common"
+	"livoir-blog/pkg/logger"
+	"livoir-blog/pkg/pagination"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+const maxSuggestQueryLength = 100
+
+var snippetReplacer = strings.NewReplacer(domain.SnippetStartSel, "<mark>", domain.SnippetStopSel, "</mark>")
+
+type searchUsecase struct {
+	searchRepo      domain.SearchRepository
+	cacheRepo       domain.CacheRepository
+	language        string
+	suggestCacheTTL time.Duration
+}
+
+// NewSearchUsecase searches with the text search configuration named by
+// language, which must match the one posts are indexed with. Suggestions are
+// cached for suggestCacheTTL.
+func NewSearchUsecase(searchRepo domain.SearchRepository, cacheRepo domain.CacheRepository, language string, suggestCacheTTL time.Duration) (domain.SearchUsecase, error) {
+	if searchRepo == nil || cacheRepo == nil {
+		return nil, errors.New("nil repository")
+	}
+	if language == "" {
+		return nil, errors.New("search language is required")
+	}
+	if suggestCacheTTL <= 0 {
+		return nil, errors.New("suggest cache ttl must be positive")
+	}
+	return &searchUsecase{
+		searchRepo:      searchRepo,
+		cacheRepo:       cacheRepo,
+		language:        language,
+		suggestCacheTTL: suggestCacheTTL,
+	}, nil
+}
+
+func (u *searchUsecase) Search(ctx context.Context, request *domain.SearchRequestDTO) (*domain.SearchResponseDTO, error) {
+	query := strings.TrimSpace(request.Query)
+	if query == "" {
+		return nil, common.NewCustomError(http.StatusBadRequest, "query is required")
+	}
+	filter := &domain.SearchFilter{
+		Query:      query,
+		Language:   u.language,
+		CategoryID: request.CategoryID,
+		Limit:      request.Limit + 1,
+	}
+	if request.Cursor != "" {
+		offset, err := pagination.DecodeOffsetCursor(request.Cursor)
+		if err != nil {
+			return nil, common.NewCustomError(http.StatusBadRequest, "invalid cursor")
+		}
+		filter.Offset = offset
+	}
+	results, err := u.searchRepo.Search(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	response := &domain.SearchResponseDTO{Results: results}
+	if len(results) > request.Limit {
+		response.Results = results[:request.Limit]
+		response.NextCursor = pagination.EncodeOffsetCursor(filter.Offset + request.Limit)
+	}
+	// Snippets are plain text, so escape them before marking the matches
+	for i := range response.Results {
+		response.Results[i].Snippet = snippetReplacer.Replace(html.EscapeString(response.Results[i].Snippet))
+	}
+	return response, nil
+}
+
+// Suggest answers from the cache when the same query was asked recently. Cache
+// failures only cost the lookup, so they are logged and the query runs anyway.
+func (u *searchUsecase) Suggest(ctx context.Context, request *domain.SuggestRequestDTO) (*domain.SuggestResponseDTO, error) {
+	query := strings.ToLower(strings.Join(strings.Fields(request.Query), " "))
+	if query == "" {
+		return nil, common.NewCustomError(http.StatusBadRequest, "query is required")
+	}
+	if utf8.RuneCountInString(query) > maxSuggestQueryLength {
+		return nil, common.NewCustomError(http.StatusBadRequest, fmt.Sprintf("query must be at most %d characters", maxSuggestQueryLength))
+	}
+	key := fmt.Sprintf("search_suggest:%d:%s", request.Limit, query)
+	cached, err := u.cacheRepo.Get(ctx, key)
+	if err != nil {
+		logger.Log.Warn("Failed to read cached suggestions", zap.Error(err))
+	}
+	if value, ok := cached.(string); ok {
+		var response domain.SuggestResponseDTO
+		if err := json.Unmarshal([]byte(value), &response); err == nil {
+			return &response, nil
+		}
+	}
+	suggestions, err := u.searchRepo.Suggest(ctx, query, request.Limit)
+	if err != nil {
+		return nil, err
+	}
+	response := &domain.SuggestResponseDTO{Suggestions: suggestions}
+	value, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.cacheRepo.Set(ctx, key, value, u.suggestCacheTTL); err != nil {
+		logger.Log.Warn("Failed to cache suggestions", zap.Error(err))
+	}
+	return response, nil
+}
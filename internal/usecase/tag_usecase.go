@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"livoir-blog/internal/domain"
+)
+
+type tagUsecase struct {
+	tagRepo domain.TagRepository
+}
+
+func NewTagUsecase(tagRepo domain.TagRepository) (domain.TagUsecase, error) {
+	if tagRepo == nil {
+		return nil, errors.New("nil repository")
+	}
+	return &tagUsecase{tagRepo: tagRepo}, nil
+}
+
+func (u *tagUsecase) List(ctx context.Context) (*domain.TagListResponseDTO, error) {
+	tags, err := u.tagRepo.ListWithPostCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.TagListResponseDTO{Tags: tags}, nil
+}
@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"livoir-blog/internal/domain"
+	"livoir-blog/pkg/common"
+	"livoir-blog/pkg/logger"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ScheduledPublisher periodically publishes drafts whose publish_at has
+// passed. Due versions are checked again under row locks, so several replicas
+// can run it side by side without publishing the same version twice.
+type ScheduledPublisher struct {
+	postUsecase domain.PostUsecase
+	interval    time.Duration
+}
+
+func NewScheduledPublisher(postUsecase domain.PostUsecase, interval time.Duration) (*ScheduledPublisher, error) {
+	if postUsecase == nil {
+		return nil, common.NewCustomError(http.StatusInternalServerError, "post usecase is nil")
+	}
+	if interval <= 0 {
+		return nil, common.NewCustomError(http.StatusInternalServerError, "scheduled publish interval must be positive")
+	}
+	return &ScheduledPublisher{
+		postUsecase: postUsecase,
+		interval:    interval,
+	}, nil
+}
+
+// Run publishes due posts on every tick until ctx is cancelled.
+func (w *ScheduledPublisher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.publishDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *ScheduledPublisher) publishDue(ctx context.Context) {
+	published, err := w.postUsecase.PublishScheduled(ctx)
+	if err != nil && ctx.Err() == nil {
+		logger.Log.Error("Failed to publish scheduled posts", zap.Error(err))
+	}
+	if published > 0 {
+		logger.Log.Info("Published scheduled posts", zap.Int("count", published))
+	}
+}
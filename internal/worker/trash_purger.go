@@ -0,0 +1,61 @@
+package worker
+
+import (
+	"context"
+	"livoir-blog/internal/domain"
+	"livoir-blog/pkg/common"
+	"livoir-blog/pkg/logger"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// TrashPurger periodically hard-deletes posts that have stayed in the trash
+// longer than the retention period.
+type TrashPurger struct {
+	postUsecase domain.PostUsecase
+	retention   time.Duration
+	interval    time.Duration
+}
+
+func NewTrashPurger(postUsecase domain.PostUsecase, retention, interval time.Duration) (*TrashPurger, error) {
+	if postUsecase == nil {
+		return nil, common.NewCustomError(http.StatusInternalServerError, "post usecase is nil")
+	}
+	if retention <= 0 {
+		return nil, common.NewCustomError(http.StatusInternalServerError, "trash retention must be positive")
+	}
+	if interval <= 0 {
+		return nil, common.NewCustomError(http.StatusInternalServerError, "trash purge interval must be positive")
+	}
+	return &TrashPurger{
+		postUsecase: postUsecase,
+		retention:   retention,
+		interval:    interval,
+	}, nil
+}
+
+// Run purges expired posts on every tick until ctx is cancelled.
+func (w *TrashPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.purge(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *TrashPurger) purge(ctx context.Context) {
+	purged, err := w.postUsecase.PurgeDeleted(ctx, time.Now().Add(-w.retention))
+	if err != nil && ctx.Err() == nil {
+		logger.Log.Error("Failed to purge deleted posts", zap.Error(err))
+	}
+	if purged > 0 {
+		logger.Log.Info("Purged deleted posts", zap.Int64("count", purged))
+	}
+}
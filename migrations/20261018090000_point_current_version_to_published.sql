@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Updates used to move current_version_id to the new draft. Public reads now
+-- serve the current version only when it is published, so point edited posts
+-- back at their latest published version.
+UPDATE posts p
+SET current_version_id = published.id
+FROM (
+    SELECT DISTINCT ON (post_id) post_id, id
+    FROM post_versions
+    WHERE published_at IS NOT NULL
+    ORDER BY post_id, version_number DESC
+) published
+WHERE published.post_id = p.id AND p.current_version_id IS DISTINCT FROM published.id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE posts p
+SET current_version_id = latest.id
+FROM (
+    SELECT DISTINCT ON (post_id) post_id, id
+    FROM post_versions
+    ORDER BY post_id, version_number DESC
+) latest
+WHERE latest.post_id = p.id AND p.current_version_id IS DISTINCT FROM latest.id;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE posts ADD COLUMN slug VARCHAR(255) NULL;
+UPDATE posts SET slug = LOWER(id);
+ALTER TABLE posts ALTER COLUMN slug SET NOT NULL;
+CREATE UNIQUE INDEX idx_posts_slug ON posts(slug);
+
+ALTER TABLE post_versions ADD COLUMN slug VARCHAR(255) NULL;
+UPDATE post_versions SET slug = LOWER(post_id);
+ALTER TABLE post_versions ALTER COLUMN slug SET NOT NULL;
+CREATE INDEX idx_post_versions_slug ON post_versions(slug);
+
+CREATE TABLE IF NOT EXISTS post_slugs (
+    slug VARCHAR(255) PRIMARY KEY,
+    post_id VARCHAR(26) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (post_id) REFERENCES posts(id)
+);
+CREATE INDEX idx_post_slugs_post_id ON post_slugs(post_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS post_slugs;
+DROP INDEX IF EXISTS idx_post_versions_slug;
+ALTER TABLE post_versions DROP COLUMN IF EXISTS slug;
+DROP INDEX IF EXISTS idx_posts_slug;
+ALTER TABLE posts DROP COLUMN IF EXISTS slug;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE post_versions ADD COLUMN publish_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+CREATE INDEX idx_post_versions_publish_at ON post_versions(publish_at) WHERE published_at IS NULL AND publish_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_post_versions_publish_at;
+ALTER TABLE post_versions DROP COLUMN IF EXISTS publish_at;
+-- +goose StatementEnd
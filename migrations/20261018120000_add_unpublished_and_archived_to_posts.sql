@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE posts ADD COLUMN unpublished_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+ALTER TABLE posts ADD COLUMN archived_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE posts DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS unpublished_at;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE post_versions ADD COLUMN content_format VARCHAR(16) NOT NULL DEFAULT 'html';
+ALTER TABLE post_versions ADD COLUMN source TEXT NULL;
+UPDATE post_versions SET source = content;
+ALTER TABLE post_versions ALTER COLUMN source SET NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE post_versions DROP COLUMN IF EXISTS source;
+ALTER TABLE post_versions DROP COLUMN IF EXISTS content_format;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE post_versions ADD COLUMN excerpt TEXT NOT NULL DEFAULT '';
+ALTER TABLE post_versions ADD COLUMN custom_excerpt BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE post_versions ADD COLUMN word_count INT NOT NULL DEFAULT 0;
+ALTER TABLE post_versions ADD COLUMN reading_time_minutes INT NOT NULL DEFAULT 0;
+-- Approximate existing versions; they are recomputed on their next update
+UPDATE post_versions SET excerpt = TRIM(regexp_replace(regexp_replace(content, '<[^>]*>', ' ', 'g'), '\s+', ' ', 'g'));
+UPDATE post_versions SET word_count = COALESCE(array_length(regexp_split_to_array(NULLIF(excerpt, ''), ' '), 1), 0);
+UPDATE post_versions SET reading_time_minutes = CEIL(word_count / 200.0), excerpt = LEFT(excerpt, 200);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE post_versions DROP COLUMN IF EXISTS reading_time_minutes;
+ALTER TABLE post_versions DROP COLUMN IF EXISTS word_count;
+ALTER TABLE post_versions DROP COLUMN IF EXISTS custom_excerpt;
+ALTER TABLE post_versions DROP COLUMN IF EXISTS excerpt;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE post_versions ADD COLUMN meta_description TEXT NOT NULL DEFAULT '';
+ALTER TABLE post_versions ADD COLUMN canonical_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE post_versions ADD COLUMN og_image TEXT NOT NULL DEFAULT '';
+ALTER TABLE post_versions ADD COLUMN og_title TEXT NOT NULL DEFAULT '';
+ALTER TABLE post_versions ADD COLUMN og_description TEXT NOT NULL DEFAULT '';
+ALTER TABLE post_versions ADD COLUMN noindex BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE post_versions DROP COLUMN IF EXISTS noindex;
+ALTER TABLE post_versions DROP COLUMN IF EXISTS og_description;
+ALTER TABLE post_versions DROP COLUMN IF EXISTS og_title;
+ALTER TABLE post_versions DROP COLUMN IF EXISTS og_image;
+ALTER TABLE post_versions DROP COLUMN IF EXISTS canonical_url;
+ALTER TABLE post_versions DROP COLUMN IF EXISTS meta_description;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS post_authors (
+    post_id VARCHAR(26) NOT NULL,
+    administrator_id VARCHAR(26) NOT NULL,
+    position INT NOT NULL,
+    display_name VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (post_id, administrator_id),
+    UNIQUE (post_id, position),
+    FOREIGN KEY (post_id) REFERENCES posts(id),
+    FOREIGN KEY (administrator_id) REFERENCES administrators(id)
+);
+CREATE INDEX idx_post_authors_administrator_id ON post_authors(administrator_id);
+ALTER TABLE post_versions ADD COLUMN editor_id VARCHAR(26) NULL REFERENCES administrators(id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE post_versions DROP COLUMN IF EXISTS editor_id;
+DROP TABLE IF EXISTS post_authors;
+-- +goose StatementEnd
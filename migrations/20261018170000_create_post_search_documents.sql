@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS post_search_documents (
+    post_id VARCHAR(26) PRIMARY KEY,
+    post_version_id VARCHAR(26) NOT NULL,
+    language REGCONFIG NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    document TSVECTOR NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (post_id) REFERENCES posts(id),
+    FOREIGN KEY (post_version_id) REFERENCES post_versions(id)
+);
+CREATE INDEX idx_post_search_documents_document ON post_search_documents USING GIN (document);
+-- Mirrors summary.PlainText, which the application indexes with: inline tags
+-- are dropped, other tags separate words and escaped entities are decoded.
+CREATE FUNCTION pg_temp.search_plain_text(content TEXT) RETURNS TEXT LANGUAGE SQL IMMUTABLE AS $$
+SELECT btrim(regexp_replace(
+    replace(replace(replace(replace(replace(replace(replace(
+        regexp_replace(
+            regexp_replace(content, '</?(a|abbr|b|cite|code|del|dfn|em|i|ins|kbd|mark|q|s|samp|small|span|strike|strong|sub|sup|time|tt|u|var)(\s[^>]*)?/?>', '', 'gi'),
+            '<[^>]*>', ' ', 'g'),
+        '&lt;', '<'), '&gt;', '>'), '&quot;', '"'), '&#34;', '"'), '&#39;', ''''), '&nbsp;', ' '), '&amp;', '&'),
+    '\s+', ' ', 'g'))
+$$;
+-- The backfill uses the default english configuration. When content.search_language
+-- differs, the application rebuilds these documents on startup.
+INSERT INTO post_search_documents (post_id, post_version_id, language, title, body, document)
+SELECT p.id, pv.id, 'english', plain.title, plain.body, setweight(to_tsvector('english', plain.title), 'A') || setweight(to_tsvector('english', plain.body), 'B')
+FROM posts p
+JOIN post_versions pv ON p.current_version_id = pv.id
+CROSS JOIN LATERAL (SELECT pg_temp.search_plain_text(pv.title) AS title, pg_temp.search_plain_text(pv.content) AS body) plain
+WHERE pv.published_at IS NOT NULL AND p.unpublished_at IS NULL AND p.archived_at IS NULL AND p.deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS post_search_documents;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX idx_post_search_documents_title_trgm ON post_search_documents USING GIN (lower(title) gin_trgm_ops);
+CREATE INDEX idx_categories_name_trgm ON categories USING GIN (lower(name) gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_categories_name_trgm;
+DROP INDEX IF EXISTS idx_post_search_documents_title_trgm;
+-- +goose StatementEnd
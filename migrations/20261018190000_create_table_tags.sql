@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS tags (
+    id VARCHAR(26) PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    slug VARCHAR(200) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS post_version_tags (
+    post_version_id VARCHAR(26) NOT NULL,
+    tag_id VARCHAR(26) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (post_version_id, tag_id),
+    FOREIGN KEY (post_version_id) REFERENCES post_versions(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id)
+);
+CREATE INDEX idx_post_version_tags_tag_id ON post_version_tags(tag_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS post_version_tags;
+DROP TABLE IF EXISTS tags;
+-- +goose StatementEnd
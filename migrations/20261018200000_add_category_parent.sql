@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS parent_id VARCHAR(26) DEFAULT NULL REFERENCES categories(id);
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories(parent_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_categories_parent_id;
+ALTER TABLE categories DROP COLUMN IF EXISTS parent_id;
+-- +goose StatementEnd
@@ -5,14 +5,22 @@ import (
 )
 
 var (
-	ErrInternalServerError   = NewCustomError(http.StatusInternalServerError, "An internal server error occurred")
-	ErrPostNotFound          = NewCustomError(http.StatusNotFound, "The requested post was not found")
-	ErrPostVersionNotFound   = NewCustomError(http.StatusNotFound, "The requested post version was not found")
-	ErrCategoryNotFound      = NewCustomError(http.StatusNotFound, "category not found")
-	ErrCategoryNameDuplicate = NewCustomError(http.StatusBadRequest, "category name already exists")
-	ErrInvalidSigningMethod  = NewCustomError(http.StatusUnauthorized, "invalid signing method")
-	ErrInvalidToken          = NewCustomError(http.StatusUnauthorized, "invalid token")
-	ErrUserNotFound          = NewCustomError(http.StatusNotFound, "user not found")
+	ErrInternalServerError    = NewCustomError(http.StatusInternalServerError, "An internal server error occurred")
+	ErrPostNotFound           = NewCustomError(http.StatusNotFound, "The requested post was not found")
+	ErrPostVersionNotFound    = NewCustomError(http.StatusNotFound, "The requested post version was not found")
+	ErrPostSlugDuplicate      = NewCustomError(http.StatusConflict, "slug is already used by another post")
+	ErrCategoryNotFound       = NewCustomError(http.StatusNotFound, "category not found")
+	ErrCategoryNameDuplicate  = NewCustomError(http.StatusBadRequest, "category name already exists")
+	ErrParentCategoryNotFound = NewCustomError(http.StatusBadRequest, "parent category not found")
+	ErrCategoryCycle          = NewCustomError(http.StatusBadRequest, "category cannot be moved under itself or its descendants")
+	ErrInvalidSigningMethod   = NewCustomError(http.StatusUnauthorized, "invalid signing method")
+	ErrInvalidToken           = NewCustomError(http.StatusUnauthorized, "invalid token")
+	ErrUserNotFound           = NewCustomError(http.StatusNotFound, "user not found")
+	ErrAuthorNotFound         = NewCustomError(http.StatusNotFound, "author not found")
+	ErrEditLeaseNotFound      = NewCustomError(http.StatusNotFound, "post is not being edited")
+	ErrEditLeaseNotHeld       = NewCustomError(http.StatusConflict, "edit lease is not held")
+	ErrAutosaveNotFound       = NewCustomError(http.StatusNotFound, "autosave not found")
+	ErrTagNotFound            = NewCustomError(http.StatusNotFound, "tag not found")
 )
 
 type CustomError struct {
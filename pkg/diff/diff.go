@@ -0,0 +1,167 @@
+package diff
+
+import (
+	"strings"
+	"unicode"
+)
+
+type Operation string
+
+const (
+	Equal  Operation = "equal"
+	Insert Operation = "insert"
+	Delete Operation = "delete"
+)
+
+// maxEditDistance bounds the work done by Compute. Inputs that differ by more
+// tokens than this are reported as a single replacement.
+const maxEditDistance = 1000
+
+// Edit is a single token of an edit script.
+type Edit struct {
+	Op   Operation
+	Text string
+}
+
+// Lines splits s into lines, keeping the trailing newline of each line.
+func Lines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Words splits s into alternating runs of whitespace and non-whitespace, so
+// that joining the tokens gives back s.
+func Words(s string) []string {
+	var tokens []string
+	start := 0
+	previousSpace := false
+	for i, r := range s {
+		space := unicode.IsSpace(r)
+		if i > start && space != previousSpace {
+			tokens = append(tokens, s[start:i])
+			start = i
+		}
+		previousSpace = space
+	}
+	if start < len(s) {
+		tokens = append(tokens, s[start:])
+	}
+	return tokens
+}
+
+// Compute returns the shortest edit script turning a into b using Myers'
+// algorithm.
+func Compute(a, b []string) []Edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	edits := make([]Edit, 0, len(a)+len(b))
+	for _, token := range a[:prefix] {
+		edits = append(edits, Edit{Equal, token})
+	}
+	edits = append(edits, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, token := range a[len(a)-suffix:] {
+		edits = append(edits, Edit{Equal, token})
+	}
+	return edits
+}
+
+func myers(a, b []string) []Edit {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return replace(a, b)
+	}
+	maxD := n + m
+	if maxD > maxEditDistance {
+		maxD = maxEditDistance
+	}
+	offset := n + m
+	v := make([]int, 2*offset+2)
+	// trace[d] holds the furthest x reached on every diagonal after step d
+	var trace [][]int
+	for d := 0; d <= maxD; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b)
+			}
+		}
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[offset-d:offset+d+1])
+		trace = append(trace, snapshot)
+	}
+	return replace(a, b)
+}
+
+func backtrack(trace [][]int, a, b []string) []Edit {
+	x, y := len(a), len(b)
+	var reversed []Edit
+	for d := len(trace); d > 0; d-- {
+		previous := trace[d-1]
+		at := func(k int) int { return previous[k+d-1] }
+		k := x - y
+		var previousK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			previousK = k + 1
+		} else {
+			previousK = k - 1
+		}
+		previousX := at(previousK)
+		previousY := previousX - previousK
+		for x > previousX && y > previousY {
+			reversed = append(reversed, Edit{Equal, a[x-1]})
+			x--
+			y--
+		}
+		if x == previousX {
+			reversed = append(reversed, Edit{Insert, b[y-1]})
+			y--
+		} else {
+			reversed = append(reversed, Edit{Delete, a[x-1]})
+			x--
+		}
+	}
+	for x > 0 && y > 0 {
+		reversed = append(reversed, Edit{Equal, a[x-1]})
+		x--
+		y--
+	}
+	edits := make([]Edit, len(reversed))
+	for i, edit := range reversed {
+		edits[len(reversed)-1-i] = edit
+	}
+	return edits
+}
+
+func replace(a, b []string) []Edit {
+	edits := make([]Edit, 0, len(a)+len(b))
+	for _, token := range a {
+		edits = append(edits, Edit{Delete, token})
+	}
+	for _, token := range b {
+		edits = append(edits, Edit{Insert, token})
+	}
+	return edits
+}
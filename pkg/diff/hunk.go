@@ -0,0 +1,133 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Change is a run of consecutive tokens sharing the same operation.
+type Change struct {
+	Op   Operation
+	Text string
+}
+
+// Hunk is a group of changes with surrounding context. Positions are 1-based
+// and count tokens, which are lines or words depending on how the input was
+// split.
+type Hunk struct {
+	FromStart int
+	FromCount int
+	ToStart   int
+	ToCount   int
+	Changes   []Change
+}
+
+// Hunks groups an edit script into hunks keeping context equal tokens around
+// every change. Changes separated by at most twice the context share a hunk.
+func Hunks(edits []Edit, context int) []Hunk {
+	fromPos := make([]int, len(edits)+1)
+	toPos := make([]int, len(edits)+1)
+	for i, edit := range edits {
+		fromPos[i+1] = fromPos[i]
+		toPos[i+1] = toPos[i]
+		if edit.Op != Insert {
+			fromPos[i+1]++
+		}
+		if edit.Op != Delete {
+			toPos[i+1]++
+		}
+	}
+	var hunks []Hunk
+	i := 0
+	for i < len(edits) {
+		for i < len(edits) && edits[i].Op == Equal {
+			i++
+		}
+		if i == len(edits) {
+			break
+		}
+		start := max(i-context, 0)
+		end := i
+		for {
+			for end < len(edits) && edits[end].Op != Equal {
+				end++
+			}
+			next := end
+			for next < len(edits) && edits[next].Op == Equal {
+				next++
+			}
+			if next < len(edits) && next-end <= 2*context {
+				end = next
+				continue
+			}
+			end = min(end+context, len(edits))
+			break
+		}
+		hunks = append(hunks, Hunk{
+			FromStart: fromPos[start] + 1,
+			FromCount: fromPos[end] - fromPos[start],
+			ToStart:   toPos[start] + 1,
+			ToCount:   toPos[end] - toPos[start],
+			Changes:   merge(edits[start:end]),
+		})
+		i = end
+	}
+	return hunks
+}
+
+func merge(edits []Edit) []Change {
+	var changes []Change
+	for _, edit := range edits {
+		if len(changes) > 0 && changes[len(changes)-1].Op == edit.Op {
+			changes[len(changes)-1].Text += edit.Text
+			continue
+		}
+		changes = append(changes, Change{Op: edit.Op, Text: edit.Text})
+	}
+	return changes
+}
+
+// WriteUnified writes hunks in unified diff format. Inline hunks, produced
+// from words, mark removed text as [-text-] and added text as {+text+}.
+func WriteUnified(b *strings.Builder, fromLabel, toLabel string, hunks []Hunk, inline bool) {
+	if len(hunks) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "--- %s\n+++ %s\n", fromLabel, toLabel)
+	for _, hunk := range hunks {
+		fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(hunk.FromStart, hunk.FromCount), hunkRange(hunk.ToStart, hunk.ToCount))
+		if inline {
+			for _, change := range hunk.Changes {
+				switch change.Op {
+				case Delete:
+					fmt.Fprintf(b, "[-%s-]", change.Text)
+				case Insert:
+					fmt.Fprintf(b, "{+%s+}", change.Text)
+				default:
+					b.WriteString(change.Text)
+				}
+			}
+			b.WriteString("\n")
+			continue
+		}
+		for _, change := range hunk.Changes {
+			prefix := " "
+			switch change.Op {
+			case Delete:
+				prefix = "-"
+			case Insert:
+				prefix = "+"
+			}
+			for _, line := range Lines(change.Text) {
+				b.WriteString(prefix + strings.TrimSuffix(line, "\n") + "\n")
+			}
+		}
+	}
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
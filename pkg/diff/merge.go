@@ -0,0 +1,81 @@
+package diff
+
+import "slices"
+
+// Conflict is a region both sides changed differently. BaseStart is the
+// 1-based position of the region in the base, counting tokens.
+type Conflict struct {
+	BaseStart int
+	Base      []string
+	Theirs    []string
+	Ours      []string
+}
+
+// Merge3 combines the changes theirs and ours made to base. Regions changed on
+// one side only take that side, and regions changed the same way on both sides
+// are kept once. Regions changed differently are reported as conflicts and
+// take ours in the merged output.
+func Merge3(base, theirs, ours []string) ([]string, []Conflict) {
+	theirsMatch := matches(base, theirs)
+	oursMatch := matches(base, ours)
+	var merged []string
+	var conflicts []Conflict
+	b, t, o := 0, 0, 0
+	for {
+		// Copy the run all three agree on
+		for b < len(base) && theirsMatch[b] == t && oursMatch[b] == o {
+			merged = append(merged, base[b])
+			b++
+			t++
+			o++
+		}
+		if b == len(base) && t == len(theirs) && o == len(ours) {
+			return merged, conflicts
+		}
+		// The unstable region ends at the next base token both sides kept
+		end, theirsEnd, oursEnd := b, len(theirs), len(ours)
+		for ; end < len(base); end++ {
+			if theirsMatch[end] >= 0 && oursMatch[end] >= 0 {
+				theirsEnd, oursEnd = theirsMatch[end], oursMatch[end]
+				break
+			}
+		}
+		baseChunk, theirsChunk, oursChunk := base[b:end], theirs[t:theirsEnd], ours[o:oursEnd]
+		switch {
+		case slices.Equal(theirsChunk, baseChunk), slices.Equal(theirsChunk, oursChunk):
+			merged = append(merged, oursChunk...)
+		case slices.Equal(oursChunk, baseChunk):
+			merged = append(merged, theirsChunk...)
+		default:
+			merged = append(merged, oursChunk...)
+			conflicts = append(conflicts, Conflict{
+				BaseStart: b + 1,
+				Base:      baseChunk,
+				Theirs:    theirsChunk,
+				Ours:      oursChunk,
+			})
+		}
+		b, t, o = end, theirsEnd, oursEnd
+	}
+}
+
+// matches maps every base token to its position in other, or -1 when the
+// shortest edit script deletes it.
+func matches(base, other []string) []int {
+	positions := make([]int, len(base))
+	b, o := 0, 0
+	for _, edit := range Compute(base, other) {
+		switch edit.Op {
+		case Equal:
+			positions[b] = o
+			b++
+			o++
+		case Delete:
+			positions[b] = -1
+			b++
+		case Insert:
+			o++
+		}
+	}
+	return positions
+}
@@ -0,0 +1,47 @@
+package etag
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+const hashLength = 16
+
+// New builds a strong entity tag from an ID and a hash of the given parts, so
+// the tag changes both when a new version is created and when a version is
+// edited in place.
+func New(id string, parts ...string) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return `"` + id + "-" + hex.EncodeToString(hash.Sum(nil))[:hashLength] + `"`
+}
+
+// ID returns the ID a single tag built by New was derived from.
+func ID(tag string) (string, bool) {
+	tag = strings.TrimSpace(tag)
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return "", false
+	}
+	separator := strings.LastIndex(tag, "-")
+	if separator < 1 {
+		return "", false
+	}
+	return tag[1:separator], true
+}
+
+// Match reports whether an If-Match header value matches the current tag. The
+// header may list several tags or be "*". Weak tags never match, as If-Match
+// uses the strong comparison.
+func Match(header, current string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == current {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+package highlight
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// PreClass marks a highlighted <pre> block. The stylesheet scopes every token
+// class under it.
+const PreClass = "chroma"
+
+var languageClass = regexp.MustCompile(`^language-([\w+#.-]+)$`)
+
+// tokenClasses matches exactly the classes Highlighter puts on token spans.
+var tokenClasses = func() *regexp.Regexp {
+	var classes []string
+	for _, class := range chroma.StandardTypes {
+		if class != "" {
+			classes = append(classes, regexp.QuoteMeta(class))
+		}
+	}
+	sort.Strings(classes)
+	return regexp.MustCompile(`^(` + strings.Join(classes, "|") + `)$`)
+}()
+
+// Highlighter turns <pre><code class="language-x"> blocks into spans classed
+// by token type, and serves the matching stylesheet.
+type Highlighter struct {
+	style *chroma.Style
+}
+
+// New returns a Highlighter for one of the chroma style names, such as
+// "github" or "monokai".
+func New(styleName string) (*Highlighter, error) {
+	style, ok := styles.Registry[styleName]
+	if !ok {
+		return nil, fmt.Errorf("unknown highlight style %q", styleName)
+	}
+	return &Highlighter{style: style}, nil
+}
+
+// TokenClasses matches the classes put on token spans, so a sanitizer can let
+// them through.
+func TokenClasses() *regexp.Regexp {
+	return tokenClasses
+}
+
+// LanguageClasses matches the language-x classes that select a lexer.
+func LanguageClasses() *regexp.Regexp {
+	return languageClass
+}
+
+// CSS returns the stylesheet for the configured style.
+func (h *Highlighter) CSS() (string, error) {
+	var b bytes.Buffer
+	err := chromahtml.New(chromahtml.WithClasses(true)).WriteCSS(&b, h.style)
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// HTML highlights every code block in content whose language is known. Other
+// blocks, and content without any, are returned unchanged.
+func (h *Highlighter) HTML(content string) (string, error) {
+	if !strings.Contains(content, "<pre") {
+		return content, nil
+	}
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(content), context)
+	if err != nil {
+		return "", err
+	}
+	highlighted := false
+	for _, node := range nodes {
+		if highlightNode(node) {
+			highlighted = true
+		}
+	}
+	if !highlighted {
+		return content, nil
+	}
+	var b bytes.Buffer
+	for _, node := range nodes {
+		if err := html.Render(&b, node); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+func highlightNode(node *html.Node) bool {
+	if node.Type == html.ElementNode && node.DataAtom == atom.Pre {
+		code := codeChild(node)
+		if code == nil {
+			return false
+		}
+		lexer := lexerFor(code)
+		if lexer == nil {
+			return false
+		}
+		tokens, err := chroma.Coalesce(lexer).Tokenise(nil, textContent(code))
+		if err != nil {
+			return false
+		}
+		for child := code.FirstChild; child != nil; child = code.FirstChild {
+			code.RemoveChild(child)
+		}
+		for _, token := range tokens.Tokens() {
+			code.AppendChild(tokenNode(token))
+		}
+		setClass(node, PreClass)
+		return true
+	}
+	highlighted := false
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if highlightNode(child) {
+			highlighted = true
+		}
+	}
+	return highlighted
+}
+
+// codeChild returns the <code> element when it is the only element in pre.
+func codeChild(pre *html.Node) *html.Node {
+	var code *html.Node
+	for child := pre.FirstChild; child != nil; child = child.NextSibling {
+		switch {
+		case child.Type == html.ElementNode && child.DataAtom == atom.Code && code == nil:
+			code = child
+		case child.Type == html.TextNode && strings.TrimSpace(child.Data) == "":
+		default:
+			return nil
+		}
+	}
+	return code
+}
+
+func lexerFor(code *html.Node) chroma.Lexer {
+	for _, attr := range code.Attr {
+		if attr.Key != "class" {
+			continue
+		}
+		for _, class := range strings.Fields(attr.Val) {
+			if match := languageClass.FindStringSubmatch(class); match != nil {
+				return lexers.Get(match[1])
+			}
+		}
+	}
+	return nil
+}
+
+func textContent(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+	var b strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
+
+func tokenNode(token chroma.Token) *html.Node {
+	text := &html.Node{Type: html.TextNode, Data: token.Value}
+	class := tokenClass(token.Type)
+	if class == "" {
+		return text
+	}
+	span := &html.Node{
+		Type:     html.ElementNode,
+		Data:     "span",
+		DataAtom: atom.Span,
+		Attr:     []html.Attribute{{Key: "class", Val: class}},
+	}
+	span.AppendChild(text)
+	return span
+}
+
+// tokenClass falls back to the parent token type, as the chroma HTML
+// formatter does, so the stylesheet covers every emitted class.
+func tokenClass(tokenType chroma.TokenType) string {
+	for tokenType != 0 {
+		if class, ok := chroma.StandardTypes[tokenType]; ok {
+			return class
+		}
+		tokenType = tokenType.Parent()
+	}
+	return ""
+}
+
+// setClass replaces the classes of node. Highlighted blocks keep only
+// PreClass, which is all sanitizers allow on them.
+func setClass(node *html.Node, class string) {
+	for i, attr := range node.Attr {
+		if attr.Key == "class" {
+			node.Attr[i].Val = class
+			return
+		}
+	}
+	node.Attr = append(node.Attr, html.Attribute{Key: "class", Val: class})
+}
@@ -0,0 +1,32 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// renderer converts CommonMark with the GitHub Flavored Markdown extensions
+// (tables, task lists, strikethrough, autolinks) and footnotes. Raw HTML is
+// passed through because the output is always sanitized afterwards.
+var renderer = goldmark.New(
+	goldmark.WithExtensions(
+		extension.GFM,
+		extension.Footnote,
+	),
+	goldmark.WithRendererOptions(
+		html.WithUnsafe(),
+	),
+)
+
+// Render converts Markdown source to HTML. The result is not safe to serve
+// until it has been sanitized.
+func Render(source string) (string, error) {
+	var b bytes.Buffer
+	if err := renderer.Convert([]byte(source), &b); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,48 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+
+	"github.com/oklog/ulid/v2"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor turns a ULID primary key into an opaque cursor string.
+func EncodeCursor(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+// DecodeCursor returns the ULID primary key stored in an opaque cursor.
+func DecodeCursor(cursor string) (string, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", ErrInvalidCursor
+	}
+	id := string(decoded)
+	if _, err := ulid.Parse(id); err != nil {
+		return "", ErrInvalidCursor
+	}
+	return id, nil
+}
+
+// EncodeOffsetCursor turns a result offset into an opaque cursor string, for
+// listings that are not ordered by primary key.
+func EncodeOffsetCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+// DecodeOffsetCursor returns the result offset stored in an opaque cursor.
+func DecodeOffsetCursor(cursor string) (int, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	offset, err := strconv.Atoi(string(decoded))
+	if err != nil || offset < 0 {
+		return 0, ErrInvalidCursor
+	}
+	return offset, nil
+}
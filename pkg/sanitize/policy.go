@@ -0,0 +1,96 @@
+package sanitize
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+const (
+	// BaseStrict removes every tag and keeps only text.
+	BaseStrict = "strict"
+	// BaseUGC keeps the formatting markup bluemonday allows for user content.
+	BaseUGC = "ugc"
+)
+
+var (
+	dataAttributeName = regexp.MustCompile(`^data-[a-z0-9]+(-[a-z0-9]+)*$`)
+	className         = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+	iframeSize        = regexp.MustCompile(`^[0-9]{1,4}%?$`)
+	iframePermissions = regexp.MustCompile(`^[a-z-]+(; ?[a-z-]+)*;?$`)
+)
+
+// PolicyConfig describes a sanitization policy: a base policy plus the extra
+// markup admins want to keep.
+type PolicyConfig struct {
+	Base           string   `mapstructure:"base"`
+	IframeHosts    []string `mapstructure:"iframe_hosts"`
+	DataAttributes []string `mapstructure:"data_attributes"`
+	Classes        []string `mapstructure:"classes"`
+	AllowFigures   bool     `mapstructure:"allow_figures"`
+}
+
+// NewPolicy builds a bluemonday policy from config. Iframes are only kept
+// when their src is an https URL on one of the allowed hosts, so scripts,
+// event handlers and javascript: URLs are still removed.
+func NewPolicy(config PolicyConfig) (*bluemonday.Policy, error) {
+	var policy *bluemonday.Policy
+	switch config.Base {
+	case BaseStrict:
+		policy = bluemonday.StrictPolicy()
+	case BaseUGC, "":
+		policy = bluemonday.UGCPolicy()
+	default:
+		return nil, fmt.Errorf("unknown base policy %q", config.Base)
+	}
+	if len(config.IframeHosts) > 0 {
+		src, err := iframeSource(config.IframeHosts)
+		if err != nil {
+			return nil, err
+		}
+		policy.AllowAttrs("src").Matching(src).OnElements("iframe")
+		policy.AllowAttrs("width", "height").Matching(iframeSize).OnElements("iframe")
+		policy.AllowAttrs("frameborder").Matching(bluemonday.Integer).OnElements("iframe")
+		policy.AllowAttrs("allow").Matching(iframePermissions).OnElements("iframe")
+		policy.AllowAttrs("loading").Matching(regexp.MustCompile(`^(lazy|eager)$`)).OnElements("iframe")
+		policy.AllowAttrs("title").OnElements("iframe")
+		policy.AllowAttrs("allowfullscreen").OnElements("iframe")
+	}
+	for _, name := range config.DataAttributes {
+		if !dataAttributeName.MatchString(name) {
+			return nil, fmt.Errorf("invalid data attribute %q", name)
+		}
+		policy.AllowAttrs(name).Globally()
+	}
+	if len(config.Classes) > 0 {
+		classes := make([]string, 0, len(config.Classes))
+		for _, class := range config.Classes {
+			if !className.MatchString(class) {
+				return nil, fmt.Errorf("invalid class %q", class)
+			}
+			classes = append(classes, regexp.QuoteMeta(class))
+		}
+		class := `(` + strings.Join(classes, "|") + `)`
+		policy.AllowAttrs("class").Matching(regexp.MustCompile(`^` + class + `( ` + class + `)*$`)).Globally()
+	}
+	if config.AllowFigures {
+		policy.AllowElements("figure", "figcaption")
+	}
+	return policy, nil
+}
+
+// iframeSource matches https URLs on exactly one of hosts.
+func iframeSource(hosts []string) (*regexp.Regexp, error) {
+	quoted := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		parsed, err := url.Parse("https://" + host)
+		if err != nil || parsed.Host != host || parsed.Hostname() == "" {
+			return nil, fmt.Errorf("invalid iframe host %q", host)
+		}
+		quoted = append(quoted, regexp.QuoteMeta(strings.ToLower(host)))
+	}
+	return regexp.MustCompile(`^https://(` + strings.Join(quoted, "|") + `)/`), nil
+}
@@ -0,0 +1,45 @@
+package slug
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const maxLength = 200
+
+// Make builds a lowercase, hyphen separated slug from s. Letters and digits of
+// any script are kept, while accents are stripped from Latin characters so
+// "Café Crème" becomes "cafe-creme".
+func Make(s string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	previousLatin := false
+	length := 0
+	for _, r := range norm.NFKD.String(s) {
+		if unicode.Is(unicode.M, r) {
+			// Marks of other scripts such as Devanagari vowel signs are part of the word
+			if !previousLatin && b.Len() > 0 && !pendingHyphen {
+				b.WriteRune(r)
+			}
+			continue
+		}
+		previousLatin = unicode.Is(unicode.Latin, r)
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			pendingHyphen = b.Len() > 0
+			continue
+		}
+		if length >= maxLength {
+			break
+		}
+		if pendingHyphen {
+			b.WriteByte('-')
+			length++
+			pendingHyphen = false
+		}
+		b.WriteRune(unicode.ToLower(r))
+		length++
+	}
+	return norm.NFC.String(b.String())
+}
@@ -0,0 +1,104 @@
+package summary
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// WordsPerMinute is the reading speed used to estimate reading time.
+const WordsPerMinute = 200
+
+const ellipsis = "…"
+
+// inlineElements do not separate words, so no space is added around them.
+var inlineElements = map[string]bool{
+	"a": true, "abbr": true, "b": true, "cite": true, "code": true, "del": true,
+	"dfn": true, "em": true, "i": true, "ins": true, "kbd": true, "mark": true,
+	"q": true, "s": true, "samp": true, "small": true, "span": true, "strike": true,
+	"strong": true, "sub": true, "sup": true, "time": true, "tt": true, "u": true,
+	"var": true,
+}
+
+// PlainText extracts the readable text of an HTML fragment with whitespace
+// collapsed to single spaces.
+func PlainText(content string) string {
+	var b strings.Builder
+	tokenizer := html.NewTokenizer(strings.NewReader(content))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return strings.Join(strings.Fields(b.String()), " ")
+		case html.TextToken:
+			b.Write(tokenizer.Text())
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			if !inlineElements[string(name)] {
+				b.WriteByte(' ')
+			}
+		}
+	}
+}
+
+// WordCount counts the words in text. Each Han, Hiragana or Katakana
+// character counts as a word since those scripts do not separate words.
+func WordCount(text string) int {
+	count := 0
+	inWord := false
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+			count++
+			inWord = false
+		case unicode.IsSpace(r):
+			inWord = false
+		default:
+			if !inWord {
+				count++
+			}
+			inWord = true
+		}
+	}
+	return count
+}
+
+// ReadingTime estimates the minutes needed to read words, rounded up.
+func ReadingTime(words int) int {
+	return (words + WordsPerMinute - 1) / WordsPerMinute
+}
+
+// Excerpt shortens text to at most maxLength characters. It ends at the last
+// complete sentence that fits, or at a word boundary followed by an ellipsis
+// when even the first sentence is too long.
+func Excerpt(text string, maxLength int) string {
+	runes := []rune(text)
+	if len(runes) <= maxLength {
+		return text
+	}
+	sentenceEnd := 0
+	for i := 0; i < maxLength; i++ {
+		switch runes[i] {
+		case '.', '!', '?':
+			if unicode.IsSpace(runes[i+1]) {
+				sentenceEnd = i + 1
+			}
+		case '。', '！', '？':
+			sentenceEnd = i + 1
+		}
+	}
+	if sentenceEnd > 0 {
+		return string(runes[:sentenceEnd])
+	}
+	// Leave room for the ellipsis
+	cut := max(maxLength-utf8.RuneCountInString(ellipsis), 0)
+	wordEnd := cut
+	for i := cut; i > 0; i-- {
+		if unicode.IsSpace(runes[i]) {
+			wordEnd = i
+			break
+		}
+	}
+	return strings.TrimRightFunc(string(runes[:wordEnd]), unicode.IsPunct) + ellipsis
+}
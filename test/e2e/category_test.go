@@ -227,6 +227,7 @@ func (suite *E2ETestSuite) TestAttachCategoryToPostVersion() {
 	req, err := http.NewRequest(http.MethodPost, "/posts", bytes.NewBuffer(jsonValue))
 	assert.NoError(t, err)
 	req.Header.Set("Content-Type", "application/json")
+	suite.setAuthorization(req)
 	w := httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
 
@@ -321,8 +322,9 @@ func (suite *E2ETestSuite) TestAttachCategoryToPostVersion() {
 		// Get post to verify category attachment
 		originalTitle := createdPost.Title
 		originalContent := createdPost.Content
-		req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s", createdPost.PostID), nil)
+		req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s/draft", createdPost.PostID), nil)
 		assert.NoError(t, err)
+		suite.setAuthorization(req)
 
 		w = httptest.NewRecorder()
 		suite.router.ServeHTTP(w, req)
@@ -346,3 +348,295 @@ func (suite *E2ETestSuite) TestAttachCategoryToPostVersion() {
 		assert.Equal(t, originalContent, postResponse["content"])
 	})
 }
+
+func (suite *E2ETestSuite) TestCarryAndReplacePostVersionCategories() {
+	t := suite.T()
+	categoryID := suite.createCategory("Carried Category")
+	otherCategoryID := suite.createCategory("Replacing Category")
+	createdPost := suite.createPost("Carried Categories", "<p>content</p>")
+	suite.attachCategories(createdPost.PostVersionID, categoryID)
+	suite.publishPost(createdPost.PostID)
+
+	versionCategories := func(versionNumber int) []string {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s/versions/%d", createdPost.PostID, versionNumber), nil)
+		assert.NoError(t, err)
+		suite.setAuthorization(req)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var version domain.PostVersionDetailDTO
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &version))
+		ids := []string{}
+		for _, category := range version.Categories {
+			ids = append(ids, category.ID)
+		}
+		return ids
+	}
+	send := func(path string, body interface{}, authorized bool) int {
+		jsonValue, err := json.Marshal(body)
+		assert.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPost, path, bytes.NewBuffer(jsonValue))
+		assert.NoError(t, err)
+		if authorized {
+			suite.setAuthorization(req)
+		}
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		return w.Code
+	}
+	post := func(path string, body interface{}) int {
+		return send(path, body, true)
+	}
+
+	// A new version starts with the categories of the one it was edited from
+	draft := suite.updatePost(createdPost.PostID, domain.UpdatePostDTO{Title: "Carried Categories", Content: "<p>edited</p>"})
+	assert.Equal(t, []string{categoryID}, versionCategories(2))
+
+	assert.Equal(t, http.StatusOK, post("/categories/replace", domain.ReplacePostVersionCategoriesRequestDTO{
+		PostVersionID: draft.PostVersionID,
+		CategoryIDs:   []string{otherCategoryID},
+	}))
+	assert.Equal(t, []string{otherCategoryID}, versionCategories(2))
+	assert.Equal(t, []string{categoryID}, versionCategories(1))
+	assert.Equal(t, http.StatusNotFound, post("/categories/replace", domain.ReplacePostVersionCategoriesRequestDTO{
+		PostVersionID: draft.PostVersionID,
+		CategoryIDs:   []string{otherCategoryID, "01JB9RGJ59B46BA25711PKMYAS"},
+	}))
+	assert.Equal(t, []string{otherCategoryID}, versionCategories(2))
+
+	// Changing the categories of a version needs a token
+	assert.Equal(t, http.StatusUnauthorized, send("/categories/replace", domain.ReplacePostVersionCategoriesRequestDTO{
+		PostVersionID: draft.PostVersionID,
+		CategoryIDs:   []string{categoryID},
+	}, false))
+	assert.Equal(t, http.StatusUnauthorized, send("/categories/detach", domain.DetachCategoryFromPostVersionRequestDTO{
+		PostVersionID: draft.PostVersionID,
+		CategoryID:    otherCategoryID,
+	}, false))
+	assert.Equal(t, []string{otherCategoryID}, versionCategories(2))
+
+	suite.detachCategory(draft.PostVersionID, otherCategoryID)
+	assert.Empty(t, versionCategories(2))
+	assert.Equal(t, http.StatusNotFound, post("/categories/detach", domain.DetachCategoryFromPostVersionRequestDTO{
+		PostVersionID: draft.PostVersionID,
+		CategoryID:    otherCategoryID,
+	}))
+	assert.Equal(t, http.StatusBadRequest, post("/categories/detach", domain.DetachCategoryFromPostVersionRequestDTO{
+		PostVersionID: draft.PostVersionID,
+		CategoryID:    "not-an-id",
+	}))
+}
+
+func (suite *E2ETestSuite) TestListAndGetCategories() {
+	t := suite.T()
+	busyID := suite.createCategory("Zz Busy Listed Category")
+	quietID := suite.createCategory("Aa Quiet Listed Category")
+	for _, title := range []string{"Busy Category Post One", "Busy Category Post Two"} {
+		createdPost := suite.createPost(title, "<p>content</p>")
+		suite.attachCategories(createdPost.PostVersionID, busyID)
+		suite.publishPost(createdPost.PostID)
+	}
+	// Drafts do not count
+	draft := suite.createPost("Busy Category Draft", "<p>content</p>")
+	suite.attachCategories(draft.PostVersionID, busyID, quietID)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodGet, path, nil)
+		assert.NoError(t, err)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+	positions := func(sort string) (map[string]int, map[string]int) {
+		w := get("/categories?sort=" + sort)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response domain.CategoryListResponseDTO
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		indexes := map[string]int{}
+		counts := map[string]int{}
+		for i, category := range response.Categories {
+			indexes[category.ID] = i
+			counts[category.ID] = category.PostCount
+		}
+		return indexes, counts
+	}
+
+	indexes, counts := positions("name")
+	assert.Equal(t, 2, counts[busyID])
+	if assert.Contains(t, counts, quietID) {
+		assert.Equal(t, 0, counts[quietID])
+	}
+	assert.Less(t, indexes[quietID], indexes[busyID])
+	indexes, _ = positions("count")
+	assert.Less(t, indexes[busyID], indexes[quietID])
+	assert.Equal(t, http.StatusBadRequest, get("/categories?sort=popularity").Code)
+
+	w := get("/categories/" + busyID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var category domain.CategoryWithPostCount
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &category))
+	assert.Equal(t, "Zz Busy Listed Category", category.Name)
+	assert.Equal(t, 2, category.PostCount)
+	assert.Equal(t, http.StatusNotFound, get("/categories/01JB9RGJ59B46BA25711PKMYAS").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/categories/not-an-id").Code)
+
+	w = get(fmt.Sprintf("/categories/%s/posts?limit=1", busyID))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var page domain.CategoryPostListResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, busyID, page.Category.ID)
+	if assert.Len(t, page.Posts, 1) {
+		assert.Equal(t, "Busy Category Post Two", page.Posts[0].Title)
+	}
+	assert.NotEmpty(t, page.NextCursor)
+	w = get(fmt.Sprintf("/categories/%s/posts?cursor=%s", busyID, page.NextCursor))
+	assert.Equal(t, http.StatusOK, w.Code)
+	page = domain.CategoryPostListResponseDTO{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	if assert.Len(t, page.Posts, 1) {
+		assert.Equal(t, "Busy Category Post One", page.Posts[0].Title)
+	}
+	assert.Empty(t, page.NextCursor)
+
+	w = get(fmt.Sprintf("/categories/%s/posts", quietID))
+	assert.Equal(t, http.StatusOK, w.Code)
+	page = domain.CategoryPostListResponseDTO{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Empty(t, page.Posts)
+	assert.Equal(t, http.StatusNotFound, get("/categories/01JB9RGJ59B46BA25711PKMYAS/posts").Code)
+}
+
+func (suite *E2ETestSuite) TestCategoryHierarchy() {
+	t := suite.T()
+	send := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			assert.NoError(t, json.NewEncoder(&buf).Encode(body))
+		}
+		req, err := http.NewRequest(method, path, &buf)
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		suite.setAuthorization(req)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+	createChild := func(name, parentID string) string {
+		w := send(http.MethodPost, "/categories", domain.CategoryRequestDTO{Name: name, ParentID: &parentID})
+		suite.Require().Equal(http.StatusCreated, w.Code)
+		var category domain.CategoryResponseDTO
+		suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &category))
+		suite.Require().NotNil(category.ParentID)
+		assert.Equal(t, parentID, *category.ParentID)
+		return category.ID
+	}
+	move := func(id, name, parentID string) *httptest.ResponseRecorder {
+		return send(http.MethodPut, "/categories/"+id, domain.CategoryRequestDTO{Name: name, ParentID: &parentID})
+	}
+	listedPostCount := func(categoryID string, includeDescendants bool) int {
+		w := send(http.MethodGet, fmt.Sprintf("/categories/%s/posts?include_descendants=%t", categoryID, includeDescendants), nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var page domain.CategoryPostListResponseDTO
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		return len(page.Posts)
+	}
+
+	engineeringID := suite.createCategory("Tree Engineering")
+	backendID := createChild("Tree Backend", engineeringID)
+	goID := createChild("Tree Go", backendID)
+	frontendID := createChild("Tree Frontend", engineeringID)
+	missingParentID := "01JB9RGJ59B46BA25711PKMYAS"
+	w := send(http.MethodPost, "/categories", domain.CategoryRequestDTO{Name: "Tree Orphan", ParentID: &missingParentID})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "parent category not found")
+
+	w = send(http.MethodGet, "/categories/tree", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var tree domain.CategoryTreeResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &tree))
+	var engineering *domain.CategoryTreeNode
+	for _, node := range tree.Categories {
+		assert.NotEqual(t, backendID, node.ID)
+		if node.ID == engineeringID {
+			engineering = node
+		}
+	}
+	if assert.NotNil(t, engineering) && assert.Len(t, engineering.Children, 2) {
+		assert.Equal(t, backendID, engineering.Children[0].ID)
+		assert.Equal(t, frontendID, engineering.Children[1].ID)
+		if assert.Len(t, engineering.Children[0].Children, 1) {
+			assert.Equal(t, goID, engineering.Children[0].Children[0].ID)
+		}
+	}
+
+	// A category cannot end up below itself
+	w = move(engineeringID, "Tree Engineering", goID)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "cannot be moved under itself")
+	assert.Equal(t, http.StatusBadRequest, move(backendID, "Tree Backend", backendID).Code)
+
+	for title, categoryID := range map[string]string{"Tree Go Post": goID, "Tree Backend Post": backendID} {
+		createdPost := suite.createPost(title, "<p>content</p>")
+		suite.attachCategories(createdPost.PostVersionID, categoryID)
+		suite.publishPost(createdPost.PostID)
+	}
+	assert.Equal(t, 0, listedPostCount(engineeringID, false))
+	assert.Equal(t, 2, listedPostCount(engineeringID, true))
+	assert.Equal(t, 1, listedPostCount(goID, true))
+
+	// Moving a category takes its posts along
+	w = move(goID, "Tree Go", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var moved domain.CategoryResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &moved))
+	assert.Nil(t, moved.ParentID)
+	assert.Equal(t, 1, listedPostCount(engineeringID, true))
+	assert.Equal(t, http.StatusOK, move(goID, "Tree Go", backendID).Code)
+
+	// Deleting a category hands its children to its parent
+	req, err := http.NewRequest(http.MethodDelete, "/categories/"+backendID, nil)
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, http.StatusOK, send(http.MethodDelete, "/categories/"+backendID, nil).Code)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodGet, "/categories/"+backendID, nil).Code)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodDelete, "/categories/"+backendID, nil).Code)
+	w = send(http.MethodGet, "/categories/"+goID, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var goCategory domain.CategoryWithPostCount
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &goCategory))
+	if assert.NotNil(t, goCategory.ParentID) {
+		assert.Equal(t, engineeringID, *goCategory.ParentID)
+	}
+	assert.Equal(t, 1, listedPostCount(engineeringID, true))
+}
+
+func (suite *E2ETestSuite) TestDeleteDraftWithCarriedCategories() {
+	t := suite.T()
+	categoryID := suite.createCategory("Discarded Draft Category")
+	createdPost := suite.createPost("Discarded Draft", "<p>content</p>")
+	suite.attachCategories(createdPost.PostVersionID, categoryID)
+	suite.publishPost(createdPost.PostID)
+	suite.updatePost(createdPost.PostID, domain.UpdatePostDTO{Title: "Discarded Draft", Content: "<p>edited</p>"})
+
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/posts/%s/draft", createdPost.PostID), nil)
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The published version keeps its categories
+	req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s", createdPost.PostID), nil)
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var retrievedPost domain.PostDetailDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &retrievedPost))
+	assert.Equal(t, "<p>content</p>", retrievedPost.Content)
+	if assert.Len(t, retrievedPost.Categories, 1) {
+		assert.Equal(t, categoryID, retrievedPost.Categories[0].ID)
+	}
+}
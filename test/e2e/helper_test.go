@@ -1,8 +1,13 @@
 package e2e
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"livoir-blog/internal/domain"
+	"net/http"
+	"net/http/httptest"
 	"time"
 )
 
@@ -25,3 +30,97 @@ func (suite *E2ETestSuite) getAccessToken(email string) (string, error) {
 	}
 	return suite.repoProvider.TokenRepository.Generate(context.Background(), tokenData)
 }
+
+func (suite *E2ETestSuite) createPost(title, content string) domain.PostResponseDTO {
+	jsonValue, err := json.Marshal(domain.CreatePostDTO{Title: title, Content: content})
+	suite.Require().NoError(err)
+	req, err := http.NewRequest(http.MethodPost, "/posts", bytes.NewBuffer(jsonValue))
+	suite.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
+	suite.setAuthorization(req)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	suite.Require().Equal(http.StatusCreated, w.Code)
+	var createdPost domain.PostResponseDTO
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &createdPost))
+	return createdPost
+}
+
+func (suite *E2ETestSuite) publishPost(postID string) {
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/posts/%s/publish", postID), nil)
+	suite.Require().NoError(err)
+	suite.setAuthorization(req)
+	req.Header.Set("If-Match", suite.draftETag(postID))
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	suite.Require().Equal(http.StatusOK, w.Code)
+}
+
+func (suite *E2ETestSuite) createCategory(name string) string {
+	jsonValue, err := json.Marshal(domain.CategoryRequestDTO{Name: name})
+	suite.Require().NoError(err)
+	req, err := http.NewRequest(http.MethodPost, "/categories", bytes.NewBuffer(jsonValue))
+	suite.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	suite.Require().Equal(http.StatusCreated, w.Code)
+	var createdCategory domain.CategoryResponseDTO
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &createdCategory))
+	return createdCategory.ID
+}
+
+func (suite *E2ETestSuite) attachCategories(postVersionID string, categoryIDs ...string) {
+	jsonValue, err := json.Marshal(domain.AttachCategoryToPostVersionRequestDTO{PostVersionID: postVersionID, CategoryIDs: categoryIDs})
+	suite.Require().NoError(err)
+	req, err := http.NewRequest(http.MethodPost, "/categories/attach", bytes.NewBuffer(jsonValue))
+	suite.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	suite.Require().Equal(http.StatusOK, w.Code)
+}
+
+func (suite *E2ETestSuite) detachCategory(postVersionID, categoryID string) {
+	jsonValue, err := json.Marshal(domain.DetachCategoryFromPostVersionRequestDTO{PostVersionID: postVersionID, CategoryID: categoryID})
+	suite.Require().NoError(err)
+	req, err := http.NewRequest(http.MethodPost, "/categories/detach", bytes.NewBuffer(jsonValue))
+	suite.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
+	suite.setAuthorization(req)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	suite.Require().Equal(http.StatusOK, w.Code)
+}
+
+// draftETag returns the entity tag of the latest version of a post, which
+// updates and publishes must send in If-Match.
+func (suite *E2ETestSuite) draftETag(postID string) string {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s/draft", postID), nil)
+	suite.Require().NoError(err)
+	suite.setAuthorization(req)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	suite.Require().Equal(http.StatusOK, w.Code)
+	return w.Header().Get("ETag")
+}
+
+func (suite *E2ETestSuite) setAuthorization(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+suite.accessToken)
+}
+
+func (suite *E2ETestSuite) updatePost(postID string, request domain.UpdatePostDTO) domain.PostResponseDTO {
+	jsonValue, err := json.Marshal(request)
+	suite.Require().NoError(err)
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/posts/%s", postID), bytes.NewBuffer(jsonValue))
+	suite.Require().NoError(err)
+	req.Header.Set("If-Match", suite.draftETag(postID))
+	req.Header.Set("Content-Type", "application/json")
+	suite.setAuthorization(req)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	suite.Require().Equal(http.StatusOK, w.Code)
+	var updatedPost domain.PostResponseDTO
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &updatedPost))
+	return updatedPost
+}
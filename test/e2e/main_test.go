@@ -5,12 +5,15 @@ import (
 	"database/sql"
 	"fmt"
 	"livoir-blog/internal/app"
+	"livoir-blog/internal/domain"
 	"livoir-blog/mocks"
 	"livoir-blog/pkg/auth"
 	"livoir-blog/pkg/cache"
 	"livoir-blog/pkg/database"
+	"livoir-blog/pkg/highlight"
 	"livoir-blog/pkg/jwt"
 	"livoir-blog/pkg/logger"
+	"livoir-blog/pkg/sanitize"
 	"os"
 	"time"
 
@@ -28,7 +31,9 @@ type E2ETestSuite struct {
 	keydbContainer      testcontainers.Container
 	mockOauthRepository *mocks.OAuthRepository
 	repoProvider        *app.RepositoryProvider
+	postUsecase         domain.PostUsecase
 	accessToken         string
+	contentConfig       app.ContentConfig
 }
 
 func (suite *E2ETestSuite) SetupSuite() {
@@ -130,7 +135,33 @@ func (suite *E2ETestSuite) SetupSuite() {
 
 	repoProvider.SetOauthRepositories(suite.mockOauthRepository, suite.mockOauthRepository)
 	suite.repoProvider = repoProvider
-	suite.router, err = app.SetupRouter(suite.db, repoProvider, encryptionKey, time.Duration(60*time.Second), time.Duration(120*time.Second))
+	suite.contentConfig = app.ContentConfig{
+		HighlightStyle: "github",
+		BodyPolicy: sanitize.PolicyConfig{
+			Base:           sanitize.BaseUGC,
+			IframeHosts:    []string{"www.youtube.com", "codepen.io"},
+			DataAttributes: []string{"data-lang"},
+			Classes:        []string{"note"},
+			AllowFigures:   true,
+		},
+		TitlePolicy:     sanitize.PolicyConfig{Base: sanitize.BaseStrict},
+		ExcerptLength:   200,
+		SearchLanguage:  "english",
+		SuggestCacheTTL: time.Duration(time.Second),
+	}
+	highlighter, err := highlight.New(suite.contentConfig.HighlightStyle)
+	if err != nil {
+		suite.T().Fatalf("failed to initialize highlighter: %s", err)
+	}
+	suite.postUsecase, err = app.NewPostUsecase(repoProvider, highlighter, suite.contentConfig)
+	if err != nil {
+		suite.T().Fatalf("failed to initialize post usecase: %s", err)
+	}
+	suite.router, err = app.SetupRouter(suite.db, repoProvider, suite.postUsecase, highlighter, encryptionKey, time.Duration(60*time.Second), time.Duration(120*time.Second), suite.contentConfig, app.EditingConfig{
+		LeaseTTL:      time.Duration(60 * time.Second),
+		AutosaveLimit: 3,
+		AutosaveTTL:   time.Duration(time.Hour),
+	})
 	if err != nil {
 		suite.T().Fatalf("failed to setup router: %s", err)
 	}
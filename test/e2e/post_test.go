@@ -2,12 +2,16 @@ package e2e
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"livoir-blog/internal/domain"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -24,6 +28,7 @@ func (suite *E2ETestSuite) TestCreateAndGetPost() {
 	req, err := http.NewRequest(http.MethodPost, "/posts", bytes.NewBuffer(jsonValue))
 	assert.NoError(suite.T(), err)
 	req.Header.Set("Content-Type", "application/json")
+	suite.setAuthorization(req)
 	w := httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
 
@@ -34,12 +39,22 @@ func (suite *E2ETestSuite) TestCreateAndGetPost() {
 	assert.NoError(suite.T(), err)
 	assert.NotEmpty(suite.T(), createdPost.PostID)
 
-	// Test getting the created post
+	// Unpublished posts are hidden from public reads
 	req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s", createdPost.PostID), nil)
 	assert.NoError(suite.T(), err)
 	w = httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
 
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	// Test getting the created post draft
+	req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s/draft", createdPost.PostID), nil)
+	assert.NoError(suite.T(), err)
+	suite.setAuthorization(req)
+	suite.setAuthorization(req)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
 	assert.Equal(suite.T(), http.StatusOK, w.Code)
 	var retrievedPost domain.PostDetailDTO
 	err = json.Unmarshal(w.Body.Bytes(), &retrievedPost)
@@ -87,6 +102,7 @@ func (suite *E2ETestSuite) TestUpdatePost() {
 	req, err := http.NewRequest(http.MethodPost, "/posts", bytes.NewBuffer(jsonValue))
 	assert.NoError(suite.T(), err)
 	req.Header.Set("Content-Type", "application/json")
+	suite.setAuthorization(req)
 	w := httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
 
@@ -104,6 +120,7 @@ func (suite *E2ETestSuite) TestUpdatePost() {
 	assert.NoError(suite.T(), err)
 	req, err = http.NewRequest("PUT", fmt.Sprintf("/posts/%s", createdPost.PostID), bytes.NewBuffer(jsonValue))
 	assert.NoError(suite.T(), err)
+	req.Header.Set("If-Match", suite.draftETag(createdPost.PostID))
 
 	w = httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
@@ -115,9 +132,11 @@ func (suite *E2ETestSuite) TestUpdatePost() {
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), "Updated Test Post", response.Title)
 
-	// Test getting the updated post
-	req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s", createdPost.PostID), nil)
+	// Test getting the updated post draft
+	req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s/draft", createdPost.PostID), nil)
 	assert.NoError(suite.T(), err)
+	suite.setAuthorization(req)
+	suite.setAuthorization(req)
 	w = httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
 
@@ -145,6 +164,7 @@ func (suite *E2ETestSuite) TestPublishPost() {
 	req, err := http.NewRequest(http.MethodPost, "/posts", bytes.NewBuffer(jsonValue))
 	assert.NoError(suite.T(), err)
 	req.Header.Set("Content-Type", "application/json")
+	suite.setAuthorization(req)
 	w := httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
 
@@ -158,6 +178,8 @@ func (suite *E2ETestSuite) TestPublishPost() {
 	// Publish the post
 	req, err = http.NewRequest(http.MethodPost, fmt.Sprintf("/posts/%s/publish", createdPost.PostID), nil)
 	assert.NoError(suite.T(), err)
+	suite.setAuthorization(req)
+	req.Header.Set("If-Match", suite.draftETag(createdPost.PostID))
 	w = httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
 
@@ -179,13 +201,15 @@ func (suite *E2ETestSuite) TestPublishPost() {
 	assert.NoError(suite.T(), err)
 	req, err = http.NewRequest(http.MethodPut, fmt.Sprintf("/posts/%s", createdPost.PostID), bytes.NewBuffer(jsonValue))
 	assert.NoError(suite.T(), err)
+	req.Header.Set("If-Match", suite.draftETag(createdPost.PostID))
 	req.Header.Set("Content-Type", "application/json")
+	suite.setAuthorization(req)
 	w = httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
 
 	assert.Equal(suite.T(), http.StatusOK, w.Code)
 
-	// Get the updated post
+	// Public reads keep serving the published version
 	req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s", createdPost.PostID), nil)
 	assert.NoError(suite.T(), err)
 	w = httptest.NewRecorder()
@@ -193,6 +217,24 @@ func (suite *E2ETestSuite) TestPublishPost() {
 
 	assert.Equal(suite.T(), http.StatusOK, w.Code)
 
+	var publicPost domain.PostDetailDTO
+	err = json.Unmarshal(w.Body.Bytes(), &publicPost)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Test Publish Post", publicPost.Title)
+	assert.Equal(suite.T(), "This is a test post content for publishing", publicPost.Content)
+	assert.Equal(suite.T(), int64(1), publicPost.VersionNumber)
+	assert.NotNil(suite.T(), publicPost.PublishedAt)
+
+	// Get the updated post draft
+	req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s/draft", createdPost.PostID), nil)
+	assert.NoError(suite.T(), err)
+	suite.setAuthorization(req)
+	suite.setAuthorization(req)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
 	var retrievedPost domain.PostDetailDTO
 	err = json.Unmarshal(w.Body.Bytes(), &retrievedPost)
 	assert.NoError(suite.T(), err)
@@ -200,12 +242,15 @@ func (suite *E2ETestSuite) TestPublishPost() {
 	assert.Equal(suite.T(), "Updated Test Publish Post", retrievedPost.Title)
 	assert.Equal(suite.T(), "This is an updated test post content for publishing", retrievedPost.Content)
 	assert.Equal(suite.T(), int64(2), retrievedPost.VersionNumber)
-	assert.NotEmpty(suite.T(), retrievedPost.CurrentVersionID)
+	assert.Equal(suite.T(), publicPost.CurrentVersionID, retrievedPost.CurrentVersionID)
+	assert.Nil(suite.T(), retrievedPost.PublishedAt)
 	assert.Empty(suite.T(), retrievedPost.Categories)
 
 	// Publish the post again
 	req, err = http.NewRequest(http.MethodPost, fmt.Sprintf("/posts/%s/publish", createdPost.PostID), nil)
 	assert.NoError(suite.T(), err)
+	suite.setAuthorization(req)
+	req.Header.Set("If-Match", suite.draftETag(createdPost.PostID))
 	w = httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
 
@@ -218,6 +263,37 @@ func (suite *E2ETestSuite) TestPublishPost() {
 	assert.NotEmpty(suite.T(), republishedPost.PublishedAt)
 	assert.Equal(suite.T(), "Updated Test Publish Post", republishedPost.Title)
 	assert.Equal(suite.T(), "This is an updated test post content for publishing", republishedPost.Content)
+
+	// Public reads now serve the republished version
+	req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s", createdPost.PostID), nil)
+	assert.NoError(suite.T(), err)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	err = json.Unmarshal(w.Body.Bytes(), &publicPost)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Updated Test Publish Post", publicPost.Title)
+	assert.Equal(suite.T(), int64(2), publicPost.VersionNumber)
+}
+
+func (suite *E2ETestSuite) TestGetPostDraftRequiresAuthentication() {
+	createdPost := suite.createPost("Test Draft Auth Post", "Draft content")
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s/draft", createdPost.PostID), nil)
+	assert.NoError(suite.T(), err)
+	suite.setAuthorization(req)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+
+	req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s/draft", createdPost.PostID), nil)
+	assert.NoError(suite.T(), err)
+	suite.setAuthorization(req)
+	req.Header.Set("Authorization", "Bearer invalid")
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
 }
 
 func (suite *E2ETestSuite) TestDeleteUnpublishedPost() {
@@ -231,6 +307,7 @@ func (suite *E2ETestSuite) TestDeleteUnpublishedPost() {
 	req, err := http.NewRequest(http.MethodPost, "/posts", bytes.NewBuffer(jsonValue))
 	assert.NoError(suite.T(), err)
 	req.Header.Set("Content-Type", "application/json")
+	suite.setAuthorization(req)
 	w := httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
 
@@ -240,9 +317,23 @@ func (suite *E2ETestSuite) TestDeleteUnpublishedPost() {
 	err = json.Unmarshal(w.Body.Bytes(), &createdPost)
 	assert.NoError(suite.T(), err)
 
+	// Publishing and deleting drafts require a token
+	req, err = http.NewRequest(http.MethodPost, fmt.Sprintf("/posts/%s/publish", createdPost.PostID), nil)
+	assert.NoError(suite.T(), err)
+	req.Header.Set("If-Match", suite.draftETag(createdPost.PostID))
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+	req, err = http.NewRequest(http.MethodDelete, fmt.Sprintf("/posts/%s/draft", createdPost.PostID), nil)
+	assert.NoError(suite.T(), err)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+
 	// Delete the unpublished post
-	req, err = http.NewRequest(http.MethodDelete, fmt.Sprintf("/posts/%s", createdPost.PostID), nil)
+	req, err = http.NewRequest(http.MethodDelete, fmt.Sprintf("/posts/%s/draft", createdPost.PostID), nil)
 	assert.NoError(suite.T(), err)
+	suite.setAuthorization(req)
 	w = httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
 	assert.Equal(suite.T(), http.StatusOK, w.Code)
@@ -267,6 +358,7 @@ func (suite *E2ETestSuite) TestDeletePublishedPost() {
 	req, err := http.NewRequest(http.MethodPost, "/posts", bytes.NewBuffer(jsonValue))
 	assert.NoError(suite.T(), err)
 	req.Header.Set("Content-Type", "application/json")
+	suite.setAuthorization(req)
 	w := httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
 
@@ -279,14 +371,17 @@ func (suite *E2ETestSuite) TestDeletePublishedPost() {
 	// Publish the post
 	req, err = http.NewRequest(http.MethodPost, fmt.Sprintf("/posts/%s/publish", createdPost.PostID), nil)
 	assert.NoError(suite.T(), err)
+	suite.setAuthorization(req)
+	req.Header.Set("If-Match", suite.draftETag(createdPost.PostID))
 	w = httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
 
 	assert.Equal(suite.T(), http.StatusOK, w.Code)
 
 	// Try to delete the published post
-	req, err = http.NewRequest(http.MethodDelete, fmt.Sprintf("/posts/%s", createdPost.PostID), nil)
+	req, err = http.NewRequest(http.MethodDelete, fmt.Sprintf("/posts/%s/draft", createdPost.PostID), nil)
 	assert.NoError(suite.T(), err)
+	suite.setAuthorization(req)
 	w = httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
 
@@ -302,6 +397,1366 @@ func (suite *E2ETestSuite) TestDeletePublishedPost() {
 	assert.Equal(suite.T(), http.StatusOK, w.Code)
 }
 
+func (suite *E2ETestSuite) TestListPosts() {
+	t := suite.T()
+	categoryID := suite.createCategory("Test Category for Listing")
+	older := suite.createPost("Test List Older Post", "Older post content")
+	suite.attachCategories(older.PostVersionID, categoryID)
+	suite.publishPost(older.PostID)
+	newer := suite.createPost("Test List Newer Post", "Newer post content")
+	suite.attachCategories(newer.PostVersionID, categoryID)
+	suite.publishPost(newer.PostID)
+	draft := suite.createPost("Test List Draft Post", "Draft post content")
+	suite.attachCategories(draft.PostVersionID, categoryID)
+
+	listPosts := func(query string) (int, domain.PostListResponseDTO) {
+		req, err := http.NewRequest(http.MethodGet, "/posts?"+query, nil)
+		assert.NoError(t, err)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		var response domain.PostListResponseDTO
+		if w.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		}
+		return w.Code, response
+	}
+
+	// First page holds the newest published post only
+	code, firstPage := listPosts("limit=1&category_id=" + categoryID)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, firstPage.Posts, 1)
+	assert.Equal(t, newer.PostID, firstPage.Posts[0].ID)
+	assert.Equal(t, "Test List Newer Post", firstPage.Posts[0].Title)
+	assert.NotNil(t, firstPage.Posts[0].PublishedAt)
+	assert.Len(t, firstPage.Posts[0].Categories, 1)
+	assert.NotEmpty(t, firstPage.NextCursor)
+
+	// Second page continues from the cursor and skips the draft
+	code, secondPage := listPosts("limit=1&category_id=" + categoryID + "&cursor=" + firstPage.NextCursor)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, secondPage.Posts, 1)
+	assert.Equal(t, older.PostID, secondPage.Posts[0].ID)
+	assert.Empty(t, secondPage.NextCursor)
+
+	// Date filters
+	code, future := listPosts("published_after=2999-01-01")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, future.Posts)
+	code, past := listPosts("published_before=2000-01-01T00:00:00Z")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, past.Posts)
+
+	// Invalid parameters
+	code, _ = listPosts("cursor=not-a-cursor")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = listPosts("limit=0")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = listPosts("category_id=invalid")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = listPosts("published_after=yesterday")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func (suite *E2ETestSuite) TestPostSlugs() {
+	t := suite.T()
+	first := suite.createPost("Café Crème Slug Test", "Slug content")
+	assert.Equal(t, "cafe-creme-slug-test", first.Slug)
+	second := suite.createPost("Café Crème Slug Test", "Slug content")
+	assert.Equal(t, "cafe-creme-slug-test-2", second.Slug)
+
+	// Explicit slugs are normalized and must be unique
+	jsonValue, err := json.Marshal(domain.CreatePostDTO{Title: "Explicit Slug Test", Content: "content", Slug: "Cafe Creme Slug Test"})
+	assert.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, "/posts", bytes.NewBuffer(jsonValue))
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	jsonValue, err = json.Marshal(domain.CreatePostDTO{Title: "Explicit Slug Test", Content: "content", Slug: "日本語 記事"})
+	assert.NoError(t, err)
+	req, err = http.NewRequest(http.MethodPost, "/posts", bytes.NewBuffer(jsonValue))
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var explicit domain.PostResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &explicit))
+	assert.Equal(t, "日本語-記事", explicit.Slug)
+
+	// Unpublished posts are not reachable through their slug
+	req, err = http.NewRequest(http.MethodGet, "/posts/slug/"+first.Slug, nil)
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	suite.publishPost(first.PostID)
+	req, err = http.NewRequest(http.MethodGet, "/posts/slug/"+first.Slug, nil)
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var retrievedPost domain.PostDetailDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &retrievedPost))
+	assert.Equal(t, first.PostID, retrievedPost.ID)
+	assert.Equal(t, first.Slug, retrievedPost.Slug)
+
+	// Renaming and republishing redirects the old slug
+	jsonValue, err = json.Marshal(domain.UpdatePostDTO{Title: "Renamed Slug Test", Content: "Slug content"})
+	assert.NoError(t, err)
+	req, err = http.NewRequest(http.MethodPut, fmt.Sprintf("/posts/%s", first.PostID), bytes.NewBuffer(jsonValue))
+	assert.NoError(t, err)
+	req.Header.Set("If-Match", suite.draftETag(first.PostID))
+	suite.setAuthorization(req)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var updated domain.PostResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.Equal(t, "renamed-slug-test", updated.Slug)
+
+	// The old slug keeps serving the published version until the draft is published
+	req, err = http.NewRequest(http.MethodGet, "/posts/slug/"+first.Slug, nil)
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	suite.publishPost(first.PostID)
+	req, err = http.NewRequest(http.MethodGet, "/posts/slug/"+first.Slug, nil)
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/posts/slug/renamed-slug-test", w.Header().Get("Location"))
+
+	req, err = http.NewRequest(http.MethodGet, "/posts/slug/renamed-slug-test", nil)
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Old slugs stay reserved for the post that used them
+	jsonValue, err = json.Marshal(domain.CreatePostDTO{Title: "Slug Thief", Content: "content", Slug: first.Slug})
+	assert.NoError(t, err)
+	req, err = http.NewRequest(http.MethodPost, "/posts", bytes.NewBuffer(jsonValue))
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Old slugs stop redirecting once the post is no longer public
+	req, err = http.NewRequest(http.MethodPost, fmt.Sprintf("/posts/%s/unpublish", first.PostID), nil)
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	req, err = http.NewRequest(http.MethodGet, "/posts/slug/"+first.Slug, nil)
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func (suite *E2ETestSuite) TestPostVersionHistory() {
+	t := suite.T()
+	categoryID := suite.createCategory("Test Category for Version History")
+	createdPost := suite.createPost("Version History v1", "First content")
+	suite.attachCategories(createdPost.PostVersionID, categoryID)
+	suite.publishPost(createdPost.PostID)
+	suite.updatePost(createdPost.PostID, domain.UpdatePostDTO{Title: "Version History v2", Content: "Second content"})
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s/versions", createdPost.PostID), nil)
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var versions domain.PostVersionListResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &versions))
+	assert.Len(t, versions.Versions, 2)
+	assert.Equal(t, int64(2), versions.Versions[0].VersionNumber)
+	assert.Equal(t, "Version History v2", versions.Versions[0].Title)
+	assert.Nil(t, versions.Versions[0].PublishedAt)
+	assert.Equal(t, int64(1), versions.Versions[1].VersionNumber)
+	assert.NotNil(t, versions.Versions[1].PublishedAt)
+	assert.NotEmpty(t, versions.Versions[1].CreatedAt)
+
+	req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s/versions/1", createdPost.PostID), nil)
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var version domain.PostVersionDetailDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &version))
+	assert.Equal(t, "Version History v1", version.Title)
+	assert.Equal(t, "First content", version.Content)
+	assert.Len(t, version.Categories, 1)
+	assert.Equal(t, categoryID, version.Categories[0].ID)
+
+	testCases := []struct {
+		name           string
+		path           string
+		authorize      bool
+		expectedStatus int
+	}{
+		{"missing token", fmt.Sprintf("/posts/%s/versions", createdPost.PostID), false, http.StatusUnauthorized},
+		{"unknown post", "/posts/01JAQDCB26N888RY1ZQ4N6N9YN/versions", true, http.StatusNotFound},
+		{"unknown version", fmt.Sprintf("/posts/%s/versions/9", createdPost.PostID), true, http.StatusNotFound},
+		{"invalid version", fmt.Sprintf("/posts/%s/versions/zero", createdPost.PostID), true, http.StatusBadRequest},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tc.path, nil)
+			assert.NoError(t, err)
+			if tc.authorize {
+				suite.setAuthorization(req)
+			}
+			w := httptest.NewRecorder()
+			suite.router.ServeHTTP(w, req)
+			assert.Equal(t, tc.expectedStatus, w.Code)
+		})
+	}
+}
+
+func (suite *E2ETestSuite) TestDiffPostVersions() {
+	t := suite.T()
+	categoryID := suite.createCategory("Test Category for Diff")
+	createdPost := suite.createPost("Diff Title", "first line\nsecond line\nthird line\n")
+	suite.attachCategories(createdPost.PostVersionID, categoryID)
+	suite.publishPost(createdPost.PostID)
+	draft := suite.updatePost(createdPost.PostID, domain.UpdatePostDTO{Title: "Diff Title Changed", Content: "first line\nsecond line edited\nthird line\n"})
+	newCategoryID := suite.createCategory("Test Category for Diff Draft")
+	suite.attachCategories(draft.PostVersionID, newCategoryID)
+	suite.detachCategory(draft.PostVersionID, categoryID)
+
+	// Defaults compare the published version with the latest draft
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s/diff", createdPost.PostID), nil)
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response domain.PostVersionDiffDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, int64(1), response.FromVersion)
+	assert.Equal(t, int64(2), response.ToVersion)
+	assert.Equal(t, domain.DiffGranularityLine, response.Granularity)
+	assert.Len(t, response.Content, 1)
+	assert.Equal(t, []domain.DiffChangeDTO{
+		{Type: "equal", Text: "first line\n"},
+		{Type: "delete", Text: "second line\n"},
+		{Type: "insert", Text: "second line edited\n"},
+		{Type: "equal", Text: "third line\n"},
+	}, response.Content[0].Changes)
+	assert.Len(t, response.Categories.Added, 1)
+	assert.Equal(t, newCategoryID, response.Categories.Added[0].ID)
+	assert.Len(t, response.Categories.Removed, 1)
+	assert.Equal(t, categoryID, response.Categories.Removed[0].ID)
+	assert.Contains(t, response.Metadata, domain.FieldChangeDTO{Field: "slug", From: createdPost.Slug, To: draft.Slug})
+
+	// Word granularity in unified format
+	req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s/diff?from=1&to=2&granularity=word&format=unified", createdPost.PostID), nil)
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Diff Title{+ Changed+}")
+	assert.Contains(t, w.Body.String(), "second line{+ edited+}")
+	assert.Contains(t, w.Body.String(), "+Test Category for Diff Draft")
+
+	for _, query := range []string{"granularity=char", "format=html", "from=0", "to=abc"} {
+		req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s/diff?%s", createdPost.PostID, query), nil)
+		assert.NoError(t, err)
+		suite.setAuthorization(req)
+		w = httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	// Markdown posts are compared on their source, not the rendered HTML
+	jsonValue, err := json.Marshal(domain.CreatePostDTO{Title: "Markdown Diff", Content: "# Heading\n\nSome *emphasis*\n", ContentFormat: domain.ContentFormatMarkdown})
+	assert.NoError(t, err)
+	req, err = http.NewRequest(http.MethodPost, "/posts", bytes.NewBuffer(jsonValue))
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var markdownPost domain.PostResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &markdownPost))
+	suite.publishPost(markdownPost.PostID)
+	suite.updatePost(markdownPost.PostID, domain.UpdatePostDTO{Title: "Markdown Diff", Content: "# Heading\n\nSome **strong** words\n"})
+	req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s/diff", markdownPost.PostID), nil)
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	response = domain.PostVersionDiffDTO{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	if assert.Len(t, response.Content, 1) {
+		assert.Equal(t, []domain.DiffChangeDTO{
+			{Type: "equal", Text: "# Heading\n\n"},
+			{Type: "delete", Text: "Some *emphasis*\n"},
+			{Type: "insert", Text: "Some **strong** words\n"},
+		}, response.Content[0].Changes)
+	}
+}
+
+func (suite *E2ETestSuite) TestRevertPost() {
+	t := suite.T()
+	categoryID := suite.createCategory("Test Category for Revert")
+	createdPost := suite.createPost("Revert Original", "Original content")
+	suite.attachCategories(createdPost.PostVersionID, categoryID)
+	suite.publishPost(createdPost.PostID)
+	suite.updatePost(createdPost.PostID, domain.UpdatePostDTO{Title: "Revert Broken Edit", Content: "Broken content"})
+	suite.publishPost(createdPost.PostID)
+
+	revert := func(request domain.RevertPostDTO) (int, domain.RevertResponseDTO) {
+		jsonValue, err := json.Marshal(request)
+		assert.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/posts/%s/revert", createdPost.PostID), bytes.NewBuffer(jsonValue))
+		assert.NoError(t, err)
+		req.Header.Set("If-Match", suite.draftETag(createdPost.PostID))
+		suite.setAuthorization(req)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		var response domain.RevertResponseDTO
+		if w.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		}
+		return w.Code, response
+	}
+
+	// Rolling back a published edit creates and publishes a new version
+	code, reverted := revert(domain.RevertPostDTO{VersionNumber: 1, Publish: true})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, int64(3), reverted.VersionNumber)
+	assert.Equal(t, int64(1), reverted.RevertedFrom)
+	assert.Equal(t, "Revert Original", reverted.Title)
+	assert.NotNil(t, reverted.PublishedAt)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s", createdPost.PostID), nil)
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var retrievedPost domain.PostDetailDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &retrievedPost))
+	assert.Equal(t, "Revert Original", retrievedPost.Title)
+	assert.Equal(t, "Original content", retrievedPost.Content)
+	assert.Equal(t, int64(3), retrievedPost.VersionNumber)
+	assert.Len(t, retrievedPost.Categories, 1)
+
+	// Without publish a draft is created, and a later revert overwrites it
+	code, reverted = revert(domain.RevertPostDTO{VersionNumber: 2})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, int64(4), reverted.VersionNumber)
+	assert.Nil(t, reverted.PublishedAt)
+	code, reverted = revert(domain.RevertPostDTO{VersionNumber: 1})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, int64(4), reverted.VersionNumber)
+	assert.Equal(t, "Revert Original", reverted.Title)
+
+	code, _ = revert(domain.RevertPostDTO{VersionNumber: 4})
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = revert(domain.RevertPostDTO{VersionNumber: 9})
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = revert(domain.RevertPostDTO{})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// Reverts must be based on the latest draft, like updates
+	jsonValue, err := json.Marshal(domain.RevertPostDTO{VersionNumber: 2, Publish: true})
+	assert.NoError(t, err)
+	req, err = http.NewRequest(http.MethodPost, fmt.Sprintf("/posts/%s/revert", createdPost.PostID), bytes.NewBuffer(jsonValue))
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+	staleETag := suite.draftETag(createdPost.PostID)
+	suite.updatePost(createdPost.PostID, domain.UpdatePostDTO{Title: "Revert Newer Draft", Content: "Newer content"})
+	req, err = http.NewRequest(http.MethodPost, fmt.Sprintf("/posts/%s/revert", createdPost.PostID), bytes.NewBuffer(jsonValue))
+	assert.NoError(t, err)
+	req.Header.Set("If-Match", staleETag)
+	suite.setAuthorization(req)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+}
+
 func TestE2E(t *testing.T) {
 	suite.Run(t, new(E2ETestSuite))
 }
+
+func (suite *E2ETestSuite) TestSchedulePost() {
+	t := suite.T()
+	createdPost := suite.createPost("Scheduled Post", "Scheduled content")
+
+	schedule := func(method string, body any) (int, domain.ScheduleResponseDTO) {
+		jsonValue, err := json.Marshal(body)
+		assert.NoError(t, err)
+		req, err := http.NewRequest(method, fmt.Sprintf("/posts/%s/schedule", createdPost.PostID), bytes.NewBuffer(jsonValue))
+		assert.NoError(t, err)
+		suite.setAuthorization(req)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		var response domain.ScheduleResponseDTO
+		if w.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		}
+		return w.Code, response
+	}
+
+	publishAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	code, scheduled := schedule(http.MethodPut, domain.SchedulePostDTO{PublishAt: publishAt})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, createdPost.PostVersionID, scheduled.PostVersionID)
+	assert.True(t, publishAt.Equal(*scheduled.PublishAt))
+
+	// Rescheduling replaces the previous time
+	publishAt = publishAt.Add(time.Hour)
+	code, scheduled = schedule(http.MethodPut, domain.SchedulePostDTO{PublishAt: publishAt})
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, publishAt.Equal(*scheduled.PublishAt))
+
+	code, scheduled = schedule(http.MethodDelete, nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Nil(t, scheduled.PublishAt)
+	code, _ = schedule(http.MethodDelete, nil)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, _ = schedule(http.MethodPut, domain.SchedulePostDTO{PublishAt: time.Now().Add(-time.Minute)})
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = schedule(http.MethodPut, map[string]string{})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// A due schedule is published by the worker's usecase call
+	_, err := suite.db.Exec("UPDATE post_versions SET publish_at = $1 WHERE id = $2", time.Now().Add(-time.Minute), createdPost.PostVersionID)
+	assert.NoError(t, err)
+	postUsecase := suite.postUsecase
+	published, err := postUsecase.PublishScheduled(context.Background())
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, published, 1)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s", createdPost.PostID), nil)
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var retrievedPost domain.PostDetailDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &retrievedPost))
+	assert.Equal(t, "Scheduled Post", retrievedPost.Title)
+	assert.NotNil(t, retrievedPost.PublishedAt)
+
+	// A published version cannot be scheduled
+	code, _ = schedule(http.MethodPut, domain.SchedulePostDTO{PublishAt: time.Now().Add(time.Hour)})
+	assert.Equal(t, http.StatusConflict, code)
+
+	// A draft that fails to publish does not hold back the ones due after it
+	failing := suite.createPost("Scheduled Failure", "Failing content")
+	later := suite.createPost("Scheduled After Failure", "Later content")
+	_, err = suite.db.Exec("UPDATE post_versions SET slug = $1, publish_at = $2 WHERE id = $3", createdPost.Slug, time.Now().Add(-2*time.Minute), failing.PostVersionID)
+	assert.NoError(t, err)
+	_, err = suite.db.Exec("UPDATE post_versions SET publish_at = $1 WHERE id = $2", time.Now().Add(-time.Minute), later.PostVersionID)
+	assert.NoError(t, err)
+	published, err = postUsecase.PublishScheduled(context.Background())
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, published, 1)
+	for postID, status := range map[string]int{failing.PostID: http.StatusNotFound, later.PostID: http.StatusOK} {
+		req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s", postID), nil)
+		assert.NoError(t, err)
+		w = httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code)
+	}
+}
+
+func (suite *E2ETestSuite) TestUnpublishAndArchivePost() {
+	t := suite.T()
+	categoryID := suite.createCategory("Test Category for Archive")
+	createdPost := suite.createPost("Archive Me", "Archived content")
+	suite.attachCategories(createdPost.PostVersionID, categoryID)
+	suite.publishPost(createdPost.PostID)
+
+	changeStatus := func(action string) (int, domain.PostStatusResponseDTO) {
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/posts/%s/%s", createdPost.PostID, action), nil)
+		assert.NoError(t, err)
+		suite.setAuthorization(req)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		var response domain.PostStatusResponseDTO
+		if w.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		}
+		return w.Code, response
+	}
+	getPost := func(path string) (int, domain.PostDetailDTO) {
+		req, err := http.NewRequest(http.MethodGet, path, nil)
+		assert.NoError(t, err)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		var response domain.PostDetailDTO
+		if w.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		}
+		return w.Code, response
+	}
+	listedIDs := func() []string {
+		req, err := http.NewRequest(http.MethodGet, "/posts?limit=100&category_id="+categoryID, nil)
+		assert.NoError(t, err)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response domain.PostListResponseDTO
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		var ids []string
+		for _, post := range response.Posts {
+			ids = append(ids, post.ID)
+		}
+		return ids
+	}
+
+	// Archived posts leave the listing but keep their permalink
+	code, status := changeStatus("archive")
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotNil(t, status.ArchivedAt)
+	assert.NotContains(t, listedIDs(), createdPost.PostID)
+	code, retrievedPost := getPost("/posts/slug/" + createdPost.Slug)
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, retrievedPost.Archived)
+	code, _ = changeStatus("archive")
+	assert.Equal(t, http.StatusConflict, code)
+
+	code, status = changeStatus("unarchive")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Nil(t, status.ArchivedAt)
+	assert.Contains(t, listedIDs(), createdPost.PostID)
+
+	// Unpublished posts disappear from public reads but keep their versions
+	code, status = changeStatus("unpublish")
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotNil(t, status.UnpublishedAt)
+	code, _ = getPost(fmt.Sprintf("/posts/%s", createdPost.PostID))
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = getPost("/posts/slug/" + createdPost.Slug)
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.NotContains(t, listedIDs(), createdPost.PostID)
+	code, _ = changeStatus("unpublish")
+	assert.Equal(t, http.StatusConflict, code)
+	code, _ = changeStatus("archive")
+	assert.Equal(t, http.StatusConflict, code)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s/versions", createdPost.PostID), nil)
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Publishing again restores the post
+	suite.publishPost(createdPost.PostID)
+	code, retrievedPost = getPost(fmt.Sprintf("/posts/%s", createdPost.PostID))
+	assert.Equal(t, http.StatusOK, code)
+	assert.Nil(t, retrievedPost.UnpublishedAt)
+	assert.False(t, retrievedPost.Archived)
+
+	req, err = http.NewRequest(http.MethodPost, fmt.Sprintf("/posts/%s/unpublish", createdPost.PostID), nil)
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func (suite *E2ETestSuite) TestTrashRestoreAndPurgePost() {
+	t := suite.T()
+	categoryID := suite.createCategory("Test Category for Trash")
+	createdPost := suite.createPost("Trash Me", "Trashed content")
+	suite.attachCategories(createdPost.PostVersionID, categoryID)
+	suite.publishPost(createdPost.PostID)
+
+	send := func(method, path string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, path, nil)
+		assert.NoError(t, err)
+		suite.setAuthorization(req)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+	trashedIDs := func() []string {
+		w := send(http.MethodGet, "/posts/trash?limit=100")
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response domain.PostListResponseDTO
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		var ids []string
+		for _, post := range response.Posts {
+			assert.NotNil(t, post.DeletedAt)
+			ids = append(ids, post.ID)
+		}
+		return ids
+	}
+	postPath := fmt.Sprintf("/posts/%s", createdPost.PostID)
+
+	// Deleted posts are hidden from every read and show up in the trash
+	w := send(http.MethodDelete, postPath)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var status domain.PostStatusResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.NotNil(t, status.DeletedAt)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodGet, postPath).Code)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodGet, postPath+"/draft").Code)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodGet, postPath+"/versions").Code)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodGet, "/posts/slug/"+createdPost.Slug).Code)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodDelete, postPath).Code)
+	assert.Contains(t, trashedIDs(), createdPost.PostID)
+
+	w = send(http.MethodPost, postPath+"/restore")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusOK, send(http.MethodGet, postPath).Code)
+	assert.NotContains(t, trashedIDs(), createdPost.PostID)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodPost, postPath+"/restore").Code)
+
+	// Posts older than the retention period are purged with their versions
+	assert.Equal(t, http.StatusOK, send(http.MethodDelete, postPath).Code)
+	_, err := suite.db.Exec("UPDATE posts SET deleted_at = $1 WHERE id = $2", time.Now().Add(-48*time.Hour), createdPost.PostID)
+	assert.NoError(t, err)
+	postUsecase := suite.postUsecase
+	purged, err := postUsecase.PurgeDeleted(context.Background(), time.Now().Add(-24*time.Hour))
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, purged, int64(1))
+	assert.NotContains(t, trashedIDs(), createdPost.PostID)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodPost, postPath+"/restore").Code)
+	var versions int
+	assert.NoError(t, suite.db.QueryRow("SELECT COUNT(*) FROM post_versions WHERE post_id = $1", createdPost.PostID).Scan(&versions))
+	assert.Zero(t, versions)
+
+	req, err := http.NewRequest(http.MethodGet, "/posts/trash", nil)
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func (suite *E2ETestSuite) TestMarkdownPost() {
+	t := suite.T()
+	source := "# Heading\n\n| a | b |\n|---|---|\n| 1 | 2 |\n\n- [x] done\n\nNote[^1] <script>alert(1)</script>\n\n[^1]: Footnote\n"
+	jsonValue, err := json.Marshal(domain.CreatePostDTO{Title: "Markdown Post", Content: source, ContentFormat: domain.ContentFormatMarkdown})
+	assert.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, "/posts", bytes.NewBuffer(jsonValue))
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var createdPost domain.PostResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &createdPost))
+	assert.Equal(t, domain.ContentFormatMarkdown, createdPost.ContentFormat)
+	assert.Equal(t, source, createdPost.Source)
+	assert.Contains(t, createdPost.Content, "<h1>Heading</h1>")
+	assert.Contains(t, createdPost.Content, "<table>")
+	assert.Contains(t, createdPost.Content, `type="checkbox"`)
+	assert.Contains(t, createdPost.Content, `id="fn:1"`)
+	assert.NotContains(t, createdPost.Content, "<script>")
+
+	// Updates keep the Markdown format unless another one is given
+	updatedPost := suite.updatePost(createdPost.PostID, domain.UpdatePostDTO{Title: "Markdown Post", Content: "Some *emphasis*"})
+	assert.Equal(t, domain.ContentFormatMarkdown, updatedPost.ContentFormat)
+	assert.Equal(t, "Some *emphasis*", updatedPost.Source)
+	assert.Contains(t, updatedPost.Content, "<em>emphasis</em>")
+
+	suite.publishPost(createdPost.PostID)
+	req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s", createdPost.PostID), nil)
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var retrievedPost domain.PostDetailDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &retrievedPost))
+	assert.Equal(t, domain.ContentFormatMarkdown, retrievedPost.ContentFormat)
+	assert.Equal(t, "Some *emphasis*", retrievedPost.Source)
+	assert.Contains(t, retrievedPost.Content, "<em>emphasis</em>")
+
+	jsonValue, err = json.Marshal(domain.CreatePostDTO{Title: "Invalid Format", Content: "content", ContentFormat: "rst"})
+	assert.NoError(t, err)
+	req, err = http.NewRequest(http.MethodPost, "/posts", bytes.NewBuffer(jsonValue))
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func (suite *E2ETestSuite) TestHighlightCodeBlocks() {
+	t := suite.T()
+	jsonValue, err := json.Marshal(domain.CreatePostDTO{
+		Title:         "Highlighted Post",
+		Content:       "```go\nfunc main() {}\n```\n\n```\nplain text\n```\n",
+		ContentFormat: domain.ContentFormatMarkdown,
+	})
+	assert.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, "/posts", bytes.NewBuffer(jsonValue))
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var createdPost domain.PostResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &createdPost))
+	assert.Contains(t, createdPost.Content, `<pre class="chroma"><code class="language-go">`)
+	assert.Contains(t, createdPost.Content, `<span class="kd">func</span>`)
+	assert.Contains(t, createdPost.Content, "<pre><code>plain text")
+
+	// HTML code blocks are highlighted too, but other classes are still stripped
+	htmlPost := suite.createPost("Highlighted HTML Post", `<pre class="note"><code class="language-python">x = 1</code></pre><p class="evil">text</p>`)
+	assert.Contains(t, htmlPost.Content, `<pre class="chroma"><code class="language-python">`)
+	assert.Contains(t, htmlPost.Content, `<span class="n">x</span>`)
+	assert.Contains(t, htmlPost.Content, "<p>text</p>")
+	assert.Equal(t, `<pre class="note"><code class="language-python">x = 1</code></pre><p>text</p>`, htmlPost.Source)
+
+	req, err = http.NewRequest(http.MethodGet, "/styles/highlight.css", nil)
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/css")
+	assert.Contains(t, w.Body.String(), ".chroma .kd")
+}
+
+func (suite *E2ETestSuite) TestContentSanitizationPolicies() {
+	t := suite.T()
+
+	// Allowed embeds and markup survive the body policy
+	embeds := suite.createPost("Embeds", `<iframe src="https://www.youtube.com/embed/abc" width="560" height="315" allowfullscreen></iframe>`+
+		`<figure class="note" data-lang="go"><img src="https://example.com/a.png"><figcaption>Caption</figcaption></figure>`)
+	assert.Contains(t, embeds.Content, `<iframe src="https://www.youtube.com/embed/abc" width="560" height="315" allowfullscreen=""></iframe>`)
+	assert.Contains(t, embeds.Content, `<figure class="note" data-lang="go">`)
+	assert.Contains(t, embeds.Content, "<figcaption>Caption</figcaption>")
+
+	// Script injection is still blocked in bodies
+	injections := map[string]string{
+		"script tag":         `<script>alert(1)</script>`,
+		"event handler":      `<img src="https://example.com/a.png" onerror="alert(1)">`,
+		"javascript link":    `<a href="javascript:alert(1)">link</a>`,
+		"svg onload":         `<svg onload="alert(1)"></svg>`,
+		"iframe other host":  `<iframe src="https://evil.example.com/embed"></iframe>`,
+		"iframe host suffix": `<iframe src="https://www.youtube.com.evil.example.com/embed"></iframe>`,
+		"iframe javascript":  `<iframe src="javascript:alert(1)"></iframe>`,
+		"iframe srcdoc":      `<iframe srcdoc="<script>alert(1)</script>" src="https://codepen.io/a/embed/b"></iframe>`,
+		"iframe handler":     `<iframe src="https://codepen.io/a/embed/b" onload="alert(1)"></iframe>`,
+		"style attribute":    `<p style="background:url(javascript:alert(1))">text</p>`,
+		"unlisted data":      `<p data-bind="alert(1)">text</p>`,
+		"unlisted class":     `<p class="note evil">text</p>`,
+	}
+	for name, content := range injections {
+		created := suite.createPost("Injection "+name, content+"<p>safe</p>")
+		assert.NotContains(t, created.Content, "<script", name)
+		assert.NotContains(t, created.Content, "alert(1)", name)
+		assert.NotContains(t, created.Content, "evil", name)
+		assert.NotContains(t, created.Content, "javascript:", name)
+	}
+
+	// Markdown goes through the same policy after rendering
+	jsonValue, err := json.Marshal(domain.CreatePostDTO{
+		Title:         "Markdown Injection",
+		Content:       "[link](javascript:alert(1))\n\n<script>alert(1)</script>\n\n<img src=x onerror=alert(1)>\n",
+		ContentFormat: domain.ContentFormatMarkdown,
+	})
+	assert.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, "/posts", bytes.NewBuffer(jsonValue))
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var markdownPost domain.PostResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &markdownPost))
+	assert.NotContains(t, markdownPost.Content, "<script")
+	assert.NotContains(t, markdownPost.Content, "alert(1)")
+	assert.NotContains(t, markdownPost.Content, "javascript:")
+
+	// Titles use their own policy, which keeps text only
+	titled := suite.createPost(`Title <b>bold</b> <iframe src="https://www.youtube.com/embed/abc"></iframe><script>alert(1)</script>`, "content")
+	assert.Equal(t, "Title bold ", titled.Title)
+}
+
+func (suite *E2ETestSuite) TestPostSummary() {
+	t := suite.T()
+	content := "<p>First sentence is <em>short</em>. " + strings.Repeat("word ", 400) + "end.</p>"
+	createdPost := suite.createPost("Summary Post", content)
+	assert.Equal(t, "First sentence is short.", createdPost.Excerpt)
+	// Excerpts cut at a word stay within the length, ellipsis included
+	unbroken := suite.createPost("Unbroken Summary Post", "<p>"+strings.Repeat("word ", 100)+"</p>")
+	assert.True(t, strings.HasSuffix(unbroken.Excerpt, "…"))
+	assert.LessOrEqual(t, utf8.RuneCountInString(unbroken.Excerpt), 200)
+
+	// A custom excerpt is kept across edits until it is reset with an empty one
+	customExcerpt := "Custom <b>summary</b>"
+	updatedPost := suite.updatePost(createdPost.PostID, domain.UpdatePostDTO{Title: "Summary Post", Content: content, Excerpt: &customExcerpt})
+	assert.Equal(t, "Custom summary", updatedPost.Excerpt)
+	updatedPost = suite.updatePost(createdPost.PostID, domain.UpdatePostDTO{Title: "Summary Post", Content: "<p>Changed content.</p>"})
+	assert.Equal(t, "Custom summary", updatedPost.Excerpt)
+	emptyExcerpt := ""
+	updatedPost = suite.updatePost(createdPost.PostID, domain.UpdatePostDTO{Title: "Summary Post", Content: content, Excerpt: &emptyExcerpt})
+	assert.Equal(t, "First sentence is short.", updatedPost.Excerpt)
+
+	suite.publishPost(createdPost.PostID)
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s", createdPost.PostID), nil)
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var retrievedPost domain.PostDetailDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &retrievedPost))
+	assert.Equal(t, "First sentence is short.", retrievedPost.Excerpt)
+	assert.Equal(t, 405, retrievedPost.WordCount)
+	assert.Equal(t, 3, retrievedPost.ReadingTimeMinutes)
+
+	req, err = http.NewRequest(http.MethodGet, "/posts", nil)
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var listResponse domain.PostListResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &listResponse))
+	found := false
+	for _, post := range listResponse.Posts {
+		if post.ID == createdPost.PostID {
+			found = true
+			assert.Equal(t, "First sentence is short.", post.Excerpt)
+			assert.Equal(t, 405, post.WordCount)
+			assert.Equal(t, 3, post.ReadingTimeMinutes)
+		}
+	}
+	assert.True(t, found)
+}
+
+func (suite *E2ETestSuite) TestPostSEO() {
+	t := suite.T()
+	createdPost := suite.createPost("SEO Post", "<p>An SEO friendly summary. More text follows here.</p>")
+	suite.publishPost(createdPost.PostID)
+
+	getPost := func() domain.PostDetailDTO {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s", createdPost.PostID), nil)
+		assert.NoError(t, err)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var post domain.PostDetailDTO
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &post))
+		return post
+	}
+
+	// Missing fields fall back to the excerpt and title
+	post := getPost()
+	assert.Equal(t, "An SEO friendly summary. More text follows here.", post.SEO.MetaDescription)
+	assert.Equal(t, "SEO Post", post.SEO.OGTitle)
+	assert.Equal(t, post.SEO.MetaDescription, post.SEO.OGDescription)
+	assert.False(t, post.SEO.NoIndex)
+	if assert.NotNil(t, post.JSONLD) {
+		assert.Equal(t, "https://schema.org", post.JSONLD.Context)
+		assert.Equal(t, "BlogPosting", post.JSONLD.Type)
+		assert.Equal(t, "SEO Post", post.JSONLD.Headline)
+		assert.Equal(t, post.SEO.MetaDescription, post.JSONLD.Description)
+		assert.NotNil(t, post.JSONLD.DatePublished)
+	}
+
+	seo := &domain.PostSEO{
+		MetaDescription: "Meta <b>description</b>",
+		CanonicalURL:    "https://example.com/seo-post",
+		OGImage:         "https://example.com/seo.png",
+		OGTitle:         "Shared Title",
+		NoIndex:         true,
+	}
+	updatedPost := suite.updatePost(createdPost.PostID, domain.UpdatePostDTO{Title: "SEO Post", Content: "<p>Updated.</p>", SEO: seo})
+	assert.Equal(t, "Meta description", updatedPost.SEO.MetaDescription)
+	assert.Equal(t, "https://example.com/seo-post", updatedPost.SEO.CanonicalURL)
+	suite.publishPost(createdPost.PostID)
+
+	post = getPost()
+	assert.Equal(t, "Meta description", post.SEO.MetaDescription)
+	assert.Equal(t, "https://example.com/seo-post", post.SEO.CanonicalURL)
+	assert.Equal(t, "https://example.com/seo.png", post.SEO.OGImage)
+	assert.Equal(t, "Shared Title", post.SEO.OGTitle)
+	assert.Equal(t, "Meta description", post.SEO.OGDescription)
+	assert.True(t, post.SEO.NoIndex)
+	if assert.NotNil(t, post.JSONLD) {
+		assert.Equal(t, "https://example.com/seo-post", post.JSONLD.URL)
+		assert.Equal(t, "https://example.com/seo.png", post.JSONLD.Image)
+	}
+
+	// SEO fields carry over to later versions when not given
+	updatedPost = suite.updatePost(createdPost.PostID, domain.UpdatePostDTO{Title: "SEO Post", Content: "<p>Updated again.</p>"})
+	assert.Equal(t, "https://example.com/seo-post", updatedPost.SEO.CanonicalURL)
+	assert.True(t, updatedPost.SEO.NoIndex)
+
+	jsonValue, err := json.Marshal(domain.UpdatePostDTO{Title: "SEO Post", Content: "content", SEO: &domain.PostSEO{CanonicalURL: "javascript:alert(1)"}})
+	assert.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/posts/%s", createdPost.PostID), bytes.NewBuffer(jsonValue))
+	assert.NoError(t, err)
+	req.Header.Set("If-Match", suite.draftETag(createdPost.PostID))
+	suite.setAuthorization(req)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func (suite *E2ETestSuite) TestPostAuthors() {
+	t := suite.T()
+	err := suite.repoProvider.AdministratorRepository.Insert(context.Background(), &domain.Administrator{
+		ID:           "idcoauthor",
+		FullName:     "Co Author",
+		Email:        "coauthor@example.com",
+		PasswordHash: "hashed_password",
+	})
+	assert.NoError(t, err)
+
+	// Authenticated editors are recorded and become the first author
+	jsonValue, err := json.Marshal(domain.CreatePostDTO{Title: "Authored Post", Content: "<p>content</p>"})
+	assert.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, "/posts", bytes.NewBuffer(jsonValue))
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var createdPost domain.PostResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &createdPost))
+	suite.publishPost(createdPost.PostID)
+
+	req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s/versions", createdPost.PostID), nil)
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var versions domain.PostVersionListResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &versions))
+	if assert.Len(t, versions.Versions, 1) && assert.NotNil(t, versions.Versions[0].EditorID) {
+		assert.Equal(t, "idadmin", *versions.Versions[0].EditorID)
+	}
+
+	getPost := func() domain.PostDetailDTO {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s", createdPost.PostID), nil)
+		assert.NoError(t, err)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var post domain.PostDetailDTO
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &post))
+		return post
+	}
+	post := getPost()
+	assert.Equal(t, []domain.Author{{ID: "idadmin", DisplayName: "test admin"}}, post.Authors)
+
+	// Authors are kept in the given order with optional display names
+	jsonValue, err = json.Marshal(domain.SetPostAuthorsDTO{Authors: []domain.PostAuthorDTO{
+		{AdministratorID: "idcoauthor"},
+		{AdministratorID: "idadmin", DisplayName: "Admin <b>Pen Name</b>"},
+	}})
+	assert.NoError(t, err)
+	req, err = http.NewRequest(http.MethodPut, fmt.Sprintf("/posts/%s/authors", createdPost.PostID), bytes.NewBuffer(jsonValue))
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	expectedAuthors := []domain.Author{{ID: "idcoauthor", DisplayName: "Co Author"}, {ID: "idadmin", DisplayName: "Admin Pen Name"}}
+	post = getPost()
+	assert.Equal(t, expectedAuthors, post.Authors)
+	if assert.NotNil(t, post.JSONLD) {
+		assert.Equal(t, []domain.Person{{Type: "Person", Name: "Co Author"}, {Type: "Person", Name: "Admin Pen Name"}}, post.JSONLD.Author)
+	}
+
+	req, err = http.NewRequest(http.MethodGet, "/authors/idcoauthor/posts", nil)
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var authorPosts domain.AuthorPostListResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &authorPosts))
+	assert.Equal(t, domain.Author{ID: "idcoauthor", DisplayName: "Co Author"}, authorPosts.Author)
+	if assert.Len(t, authorPosts.Posts, 1) {
+		assert.Equal(t, createdPost.PostID, authorPosts.Posts[0].ID)
+		assert.Equal(t, expectedAuthors, authorPosts.Posts[0].Authors)
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           interface{}
+		authorize      bool
+		expectedStatus int
+	}{
+		{"unknown author listing", http.MethodGet, "/authors/idunknown/posts", nil, false, http.StatusNotFound},
+		{"create post without token", http.MethodPost, "/posts", domain.CreatePostDTO{Title: "Anonymous Post", Content: "content"}, false, http.StatusUnauthorized},
+		{"update post without token", http.MethodPut, fmt.Sprintf("/posts/%s", createdPost.PostID), domain.UpdatePostDTO{Title: "Anonymous Edit", Content: "content"}, false, http.StatusUnauthorized},
+		{"set authors without token", http.MethodPut, fmt.Sprintf("/posts/%s/authors", createdPost.PostID), domain.SetPostAuthorsDTO{Authors: []domain.PostAuthorDTO{{AdministratorID: "idadmin"}}}, false, http.StatusUnauthorized},
+		{"no authors", http.MethodPut, fmt.Sprintf("/posts/%s/authors", createdPost.PostID), domain.SetPostAuthorsDTO{}, true, http.StatusBadRequest},
+		{"repeated author", http.MethodPut, fmt.Sprintf("/posts/%s/authors", createdPost.PostID), domain.SetPostAuthorsDTO{Authors: []domain.PostAuthorDTO{{AdministratorID: "idadmin"}, {AdministratorID: "idadmin"}}}, true, http.StatusBadRequest},
+		{"unknown administrator", http.MethodPut, fmt.Sprintf("/posts/%s/authors", createdPost.PostID), domain.SetPostAuthorsDTO{Authors: []domain.PostAuthorDTO{{AdministratorID: "idunknown"}}}, true, http.StatusBadRequest},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var body bytes.Buffer
+			if tc.body != nil {
+				assert.NoError(t, json.NewEncoder(&body).Encode(tc.body))
+			}
+			req, err := http.NewRequest(tc.method, tc.path, &body)
+			assert.NoError(t, err)
+			if tc.authorize {
+				suite.setAuthorization(req)
+			}
+			w := httptest.NewRecorder()
+			suite.router.ServeHTTP(w, req)
+			assert.Equal(t, tc.expectedStatus, w.Code)
+		})
+	}
+
+	// An invalid token is rejected like a missing one
+	req, err = http.NewRequest(http.MethodPost, "/posts", bytes.NewBuffer(jsonValue))
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer invalid")
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func (suite *E2ETestSuite) TestPostETags() {
+	t := suite.T()
+	jsonValue, err := json.Marshal(domain.CreatePostDTO{Title: "ETag Post", Content: "<p>content</p>"})
+	assert.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, "/posts", bytes.NewBuffer(jsonValue))
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var createdPost domain.PostResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &createdPost))
+	createdETag := w.Header().Get("ETag")
+	assert.NotEmpty(t, createdETag)
+	assert.Equal(t, createdETag, suite.draftETag(createdPost.PostID))
+
+	send := func(method, path string, body interface{}, ifMatch string) *httptest.ResponseRecorder {
+		var buffer bytes.Buffer
+		if body != nil {
+			assert.NoError(t, json.NewEncoder(&buffer).Encode(body))
+		}
+		req, err := http.NewRequest(method, path, &buffer)
+		assert.NoError(t, err)
+		suite.setAuthorization(req)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+	postPath := fmt.Sprintf("/posts/%s", createdPost.PostID)
+
+	// Updates and publishes require If-Match
+	w = send(http.MethodPut, postPath, domain.UpdatePostDTO{Title: "ETag Post", Content: "<p>first edit</p>"}, "")
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+	w = send(http.MethodPost, postPath+"/publish", nil, "")
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+
+	// The first editor wins and gets a new tag, even though the draft is overwritten in place
+	w = send(http.MethodPut, postPath, domain.UpdatePostDTO{Title: "ETag Post", Content: "<p>first edit</p>"}, createdETag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	firstEditETag := w.Header().Get("ETag")
+	assert.NotEqual(t, createdETag, firstEditETag)
+
+	// The second editor still holds the old tag and gets the current state back
+	w = send(http.MethodPut, postPath, domain.UpdatePostDTO{Title: "ETag Post", Content: "<p>second edit</p>"}, createdETag)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, firstEditETag, w.Header().Get("ETag"))
+	var stale struct {
+		Error   string               `json:"error"`
+		Current domain.PostDetailDTO `json:"current"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &stale))
+	assert.Equal(t, "<p>first edit</p>", stale.Current.Content)
+
+	w = send(http.MethodPost, postPath+"/publish", nil, createdETag)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	w = send(http.MethodPost, postPath+"/publish", nil, `"other", `+firstEditETag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, firstEditETag, w.Header().Get("ETag"))
+
+	// Public reads return the tag of the published version
+	w = send(http.MethodGet, postPath, nil, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, firstEditETag, w.Header().Get("ETag"))
+}
+
+func (suite *E2ETestSuite) TestMergeStalePostUpdate() {
+	t := suite.T()
+	source := "# Title\n\nFirst paragraph.\n\nSecond paragraph.\n\nThird paragraph.\n"
+	jsonValue, err := json.Marshal(domain.CreatePostDTO{Title: "Merge Post", Content: source, ContentFormat: domain.ContentFormatMarkdown})
+	assert.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, "/posts", bytes.NewBuffer(jsonValue))
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var createdPost domain.PostResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &createdPost))
+	suite.publishPost(createdPost.PostID)
+	publishedETag := suite.draftETag(createdPost.PostID)
+
+	update := func(request domain.UpdatePostDTO, ifMatch string) *httptest.ResponseRecorder {
+		jsonValue, err := json.Marshal(request)
+		assert.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/posts/%s", createdPost.PostID), bytes.NewBuffer(jsonValue))
+		assert.NoError(t, err)
+		req.Header.Set("If-Match", ifMatch)
+		suite.setAuthorization(req)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Both editors start from the published version
+	w = update(domain.UpdatePostDTO{Title: "Merge Post", Content: strings.Replace(source, "First paragraph.", "First paragraph, edited.", 1)}, publishedETag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	firstETag := w.Header().Get("ETag")
+
+	// Edits to other lines merge cleanly into the draft
+	w = update(domain.UpdatePostDTO{Title: "Merge Post", Content: strings.Replace(source, "Third paragraph.", "Third paragraph, edited.", 1)}, publishedETag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var mergedPost domain.PostResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &mergedPost))
+	assert.True(t, mergedPost.Merged)
+	assert.Equal(t, "# Title\n\nFirst paragraph, edited.\n\nSecond paragraph.\n\nThird paragraph, edited.\n", mergedPost.Source)
+	assert.Contains(t, mergedPost.Content, "<p>First paragraph, edited.</p>")
+	assert.Contains(t, mergedPost.Content, "<p>Third paragraph, edited.</p>")
+
+	// Different edits to the same line are returned as conflicts
+	w = update(domain.UpdatePostDTO{Title: "Merge Post", Content: strings.Replace(source, "First paragraph.", "First paragraph, rewritten.", 1)}, publishedETag)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var conflict struct {
+		Current   domain.PostDetailDTO      `json:"current"`
+		Conflicts []domain.MergeConflictDTO `json:"conflicts"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &conflict))
+	assert.Equal(t, mergedPost.Source, conflict.Current.Source)
+	assert.Equal(t, []domain.MergeConflictDTO{{
+		Field:     "content",
+		BaseStart: 3,
+		Base:      "First paragraph.\n",
+		Theirs:    "First paragraph, edited.\n",
+		Ours:      "First paragraph, rewritten.\n",
+	}}, conflict.Conflicts)
+
+	// Older tags of the draft still merge against the content they were read from
+	w = update(domain.UpdatePostDTO{Title: "Merge Post", Content: source}, firstETag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &mergedPost))
+	assert.True(t, mergedPost.Merged)
+	assert.Equal(t, strings.Replace(source, "Third paragraph.", "Third paragraph, edited.", 1), mergedPost.Source)
+
+	w = update(domain.UpdatePostDTO{Title: "Merge Post", Content: source}, `"`+createdPost.PostVersionID+`-0000000000000000"`)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+}
+
+func (suite *E2ETestSuite) TestMergeStaleDraftUpdates() {
+	t := suite.T()
+	source := "# Draft\n\nIntro.\n\n## Setup\n\nInstall it.\n\n## Usage\n\nRun it.\n"
+	jsonValue, err := json.Marshal(domain.CreatePostDTO{Title: "Shared Draft", Content: source, ContentFormat: domain.ContentFormatMarkdown})
+	assert.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, "/posts", bytes.NewBuffer(jsonValue))
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var createdPost domain.PostResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &createdPost))
+	// Both editors open the same unpublished draft
+	readETag := w.Header().Get("ETag")
+
+	update := func(content string) (domain.PostResponseDTO, string) {
+		jsonValue, err := json.Marshal(domain.UpdatePostDTO{Title: "Shared Draft", Content: content})
+		assert.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/posts/%s", createdPost.PostID), bytes.NewBuffer(jsonValue))
+		assert.NoError(t, err)
+		req.Header.Set("If-Match", readETag)
+		suite.setAuthorization(req)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var updated domain.PostResponseDTO
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+		return updated, w.Header().Get("ETag")
+	}
+
+	first, _ := update(strings.Replace(source, "Install it.", "Install it with go get.", 1))
+	assert.False(t, first.Merged)
+	second, secondETag := update(strings.Replace(source, "Run it.", "Run it from the shell.", 1))
+	assert.True(t, second.Merged)
+	assert.Equal(t, createdPost.PostVersionID, second.PostVersionID)
+	assert.Equal(t, "# Draft\n\nIntro.\n\n## Setup\n\nInstall it with go get.\n\n## Usage\n\nRun it from the shell.\n", second.Source)
+	assert.Equal(t, secondETag, suite.draftETag(createdPost.PostID))
+}
+
+func (suite *E2ETestSuite) TestPostEditLease() {
+	t := suite.T()
+	err := suite.repoProvider.AdministratorRepository.Insert(context.Background(), &domain.Administrator{
+		ID:           "idleaseholder",
+		FullName:     "Lease Holder",
+		Email:        "leaseholder@example.com",
+		PasswordHash: "hashed_password",
+	})
+	assert.NoError(t, err)
+	holderToken, err := suite.getAccessToken("leaseholder@example.com")
+	assert.NoError(t, err)
+	createdPost := suite.createPost("Leased Post", "<p>content</p>")
+	suite.publishPost(createdPost.PostID)
+	suite.updatePost(createdPost.PostID, domain.UpdatePostDTO{Title: "Leased Post", Content: "<p>second</p>"})
+
+	lease := func(method, token, query string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, fmt.Sprintf("/posts/%s/lease%s", createdPost.PostID, query), nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+	update := func() *httptest.ResponseRecorder {
+		jsonValue, err := json.Marshal(domain.UpdatePostDTO{Title: "Leased Post", Content: "<p>edited</p>"})
+		assert.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/posts/%s", createdPost.PostID), bytes.NewBuffer(jsonValue))
+		assert.NoError(t, err)
+		req.Header.Set("If-Match", suite.draftETag(createdPost.PostID))
+		suite.setAuthorization(req)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+	revert := func() *httptest.ResponseRecorder {
+		jsonValue, err := json.Marshal(domain.RevertPostDTO{VersionNumber: 1})
+		assert.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/posts/%s/revert", createdPost.PostID), bytes.NewBuffer(jsonValue))
+		assert.NoError(t, err)
+		req.Header.Set("If-Match", suite.draftETag(createdPost.PostID))
+		suite.setAuthorization(req)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := lease(http.MethodPost, holderToken, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var acquired domain.EditLeaseResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &acquired))
+	assert.True(t, acquired.Acquired)
+	if assert.NotNil(t, acquired.Lease) {
+		assert.Equal(t, "idleaseholder", acquired.Lease.HolderID)
+		assert.Equal(t, "Lease Holder", acquired.Lease.HolderName)
+	}
+
+	// Other editors see who holds the lease and cannot write
+	w = lease(http.MethodPost, suite.accessToken, "")
+	assert.Equal(t, http.StatusLocked, w.Code)
+	var held struct {
+		Lease domain.EditLease `json:"lease"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &held))
+	assert.Equal(t, "Lease Holder", held.Lease.HolderName)
+	assert.Equal(t, http.StatusLocked, update().Code)
+	assert.Equal(t, http.StatusLocked, revert().Code)
+	assert.Equal(t, http.StatusConflict, lease(http.MethodPut, suite.accessToken, "").Code)
+	assert.Equal(t, http.StatusConflict, lease(http.MethodDelete, suite.accessToken, "").Code)
+
+	// The holder keeps the lease alive with heartbeats
+	assert.Equal(t, http.StatusOK, lease(http.MethodPut, holderToken, "").Code)
+	w = lease(http.MethodGet, suite.accessToken, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The author of the post can break the lease
+	assert.Equal(t, http.StatusOK, lease(http.MethodDelete, suite.accessToken, "?force=true").Code)
+	assert.Equal(t, http.StatusNotFound, lease(http.MethodGet, suite.accessToken, "").Code)
+	assert.Equal(t, http.StatusOK, update().Code)
+	assert.Equal(t, http.StatusOK, revert().Code)
+}
+
+func (suite *E2ETestSuite) TestPostAutosaves() {
+	t := suite.T()
+	createdPost := suite.createPost("Autosaved Post", "<p>content</p>")
+	draftETag := suite.draftETag(createdPost.PostID)
+
+	autosave := func(content string) domain.Autosave {
+		jsonValue, err := json.Marshal(domain.UpdatePostDTO{Title: "Autosaved Post", Content: content})
+		assert.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/posts/%s/autosaves", createdPost.PostID), bytes.NewBuffer(jsonValue))
+		assert.NoError(t, err)
+		suite.setAuthorization(req)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var saved domain.Autosave
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &saved))
+		return saved
+	}
+	listAutosaves := func() []domain.Autosave {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%s/autosaves", createdPost.PostID), nil)
+		assert.NoError(t, err)
+		suite.setAuthorization(req)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response domain.AutosaveListResponseDTO
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Autosaves
+	}
+
+	// Only the newest snapshots are kept and the draft is left alone
+	for i := 1; i <= 4; i++ {
+		autosave(fmt.Sprintf("<p>autosave %d</p>", i))
+	}
+	autosaves := listAutosaves()
+	if assert.Len(t, autosaves, 3) {
+		assert.Equal(t, "<p>autosave 4</p>", autosaves[0].Content)
+		assert.Equal(t, "<p>autosave 2</p>", autosaves[2].Content)
+	}
+	assert.Equal(t, draftETag, suite.draftETag(createdPost.PostID))
+
+	// Saving promotes a snapshot into the draft version
+	promote := func(autosaveID, etag string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/posts/%s/autosaves/%s/promote", createdPost.PostID, autosaveID), nil)
+		assert.NoError(t, err)
+		req.Header.Set("If-Match", etag)
+		suite.setAuthorization(req)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+	w := promote(autosaves[1].ID, draftETag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var promoted domain.PostResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &promoted))
+	assert.Equal(t, "<p>autosave 3</p>", promoted.Content)
+	assert.Equal(t, promoted.ETag, w.Header().Get("ETag"))
+	assert.Empty(t, listAutosaves())
+	assert.Equal(t, http.StatusNotFound, promote(autosaves[0].ID, promoted.ETag).Code)
+
+	autosave("<p>discarded</p>")
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/posts/%s/autosaves", createdPost.PostID), nil)
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, listAutosaves())
+}
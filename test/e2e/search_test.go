@@ -0,0 +1,147 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"livoir-blog/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *E2ETestSuite) TestSearchPosts() {
+	t := suite.T()
+	search := func(query string) (int, domain.SearchResponseDTO) {
+		req, err := http.NewRequest(http.MethodGet, "/search?"+query, nil)
+		assert.NoError(t, err)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		var response domain.SearchResponseDTO
+		if w.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		}
+		return w.Code, response
+	}
+
+	titleMatch := suite.createPost("Zephyrine Gardens", "<p>Notes about plants.</p>")
+	suite.publishPost(titleMatch.PostID)
+	bodyMatch := suite.createPost("Greenhouse Notes", "<p>We visited the zephyrine greenhouse &amp; saw <b>ferns</b>.</p>")
+	categoryID := suite.createCategory("Search Category")
+	suite.attachCategories(bodyMatch.PostVersionID, categoryID)
+	suite.publishPost(bodyMatch.PostID)
+	suite.createPost("Zephyrine Draft", "<p>Not published yet.</p>")
+
+	// Title matches rank above body matches and drafts are left out
+	code, response := search("q=zephyrine")
+	assert.Equal(t, http.StatusOK, code)
+	if assert.Len(t, response.Results, 2) {
+		assert.Equal(t, titleMatch.PostID, response.Results[0].PostID)
+		assert.Equal(t, bodyMatch.PostID, response.Results[1].PostID)
+		assert.Greater(t, response.Results[0].Rank, response.Results[1].Rank)
+		assert.Contains(t, response.Results[1].Snippet, "<mark>zephyrine</mark> greenhouse &amp; saw ferns")
+	}
+
+	code, response = search("q=zephyrine&limit=1")
+	assert.Equal(t, http.StatusOK, code)
+	if assert.Len(t, response.Results, 1) && assert.NotEmpty(t, response.NextCursor) {
+		assert.Equal(t, titleMatch.PostID, response.Results[0].PostID)
+		code, response = search("q=zephyrine&limit=1&cursor=" + url.QueryEscape(response.NextCursor))
+		assert.Equal(t, http.StatusOK, code)
+		if assert.Len(t, response.Results, 1) {
+			assert.Equal(t, bodyMatch.PostID, response.Results[0].PostID)
+		}
+		assert.Empty(t, response.NextCursor)
+	}
+
+	code, response = search(fmt.Sprintf("q=zephyrine&category_id=%s", categoryID))
+	assert.Equal(t, http.StatusOK, code)
+	if assert.Len(t, response.Results, 1) {
+		assert.Equal(t, bodyMatch.PostID, response.Results[0].PostID)
+	}
+
+	// The index follows the current version, so drafts show up once published
+	suite.updatePost(titleMatch.PostID, domain.UpdatePostDTO{Title: "Quartzite Gardens", Content: "<p>Notes about stones.</p>"})
+	_, response = search("q=quartzite")
+	assert.Empty(t, response.Results)
+	suite.publishPost(titleMatch.PostID)
+	_, response = search("q=quartzite")
+	if assert.Len(t, response.Results, 1) {
+		assert.Equal(t, "Quartzite Gardens", response.Results[0].Title)
+	}
+	_, response = search("q=zephyrine")
+	assert.Len(t, response.Results, 1)
+
+	// Archived posts drop out of search like they do from the listing
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/posts/%s/archive", bodyMatch.PostID), nil)
+	assert.NoError(t, err)
+	suite.setAuthorization(req)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	_, response = search("q=zephyrine")
+	assert.Empty(t, response.Results)
+
+	// Documents built with another language are rebuilt by a reindex
+	_, err = suite.db.Exec("UPDATE post_search_documents SET language = 'simple' WHERE post_id = $1", titleMatch.PostID)
+	assert.NoError(t, err)
+	reindexed, err := suite.postUsecase.ReindexSearch(context.Background())
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, reindexed, 1)
+	var language string
+	assert.NoError(t, suite.db.QueryRow("SELECT language::text FROM post_search_documents WHERE post_id = $1", titleMatch.PostID).Scan(&language))
+	assert.Equal(t, "english", language)
+
+	code, _ = search("q=")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func (suite *E2ETestSuite) TestSuggestSearchTerms() {
+	t := suite.T()
+	suggest := func(query string) (int, domain.SuggestResponseDTO) {
+		req, err := http.NewRequest(http.MethodGet, "/search/suggest?q="+url.QueryEscape(query), nil)
+		assert.NoError(t, err)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		var response domain.SuggestResponseDTO
+		if w.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		}
+		return w.Code, response
+	}
+
+	post := suite.createPost("Xylophonist Handbook", "<p>Mallets and scales.</p>")
+	suite.publishPost(post.PostID)
+	suite.createPost("Xylophonist Drafts", "<p>Not published yet.</p>")
+	categoryID := suite.createCategory("Learning Xylophonics")
+
+	// Typos still match, and titles starting with the query rank first
+	code, response := suggest("Xylophonst")
+	assert.Equal(t, http.StatusOK, code)
+	if assert.NotEmpty(t, response.Suggestions) {
+		assert.Equal(t, domain.Suggestion{
+			Type:  domain.SuggestionTypePost,
+			ID:    post.PostID,
+			Slug:  post.Slug,
+			Text:  "Xylophonist Handbook",
+			Score: response.Suggestions[0].Score,
+		}, response.Suggestions[0])
+	}
+
+	code, response = suggest("xylophon")
+	assert.Equal(t, http.StatusOK, code)
+	if assert.Len(t, response.Suggestions, 2) {
+		assert.Equal(t, post.PostID, response.Suggestions[0].ID)
+		assert.Equal(t, domain.SuggestionTypeCategory, response.Suggestions[1].Type)
+		assert.Equal(t, categoryID, response.Suggestions[1].ID)
+		assert.Greater(t, response.Suggestions[0].Score, response.Suggestions[1].Score)
+	}
+	cached, err := suite.repoProvider.CacheRepository.Has(context.Background(), "search_suggest:10:xylophon")
+	assert.NoError(t, err)
+	assert.True(t, cached)
+
+	code, _ = suggest(" ")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
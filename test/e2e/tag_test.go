@@ -0,0 +1,103 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"livoir-blog/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *E2ETestSuite) TestPostTags() {
+	t := suite.T()
+	createPost := func(title string, tags []string) *httptest.ResponseRecorder {
+		jsonValue, err := json.Marshal(domain.CreatePostDTO{Title: title, Content: "<p>content</p>", Tags: tags})
+		assert.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPost, "/posts", bytes.NewBuffer(jsonValue))
+		assert.NoError(t, err)
+		suite.setAuthorization(req)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+	tagCounts := func() map[string]int {
+		req, err := http.NewRequest(http.MethodGet, "/tags", nil)
+		assert.NoError(t, err)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response domain.TagListResponseDTO
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		counts := map[string]int{}
+		for _, tag := range response.Tags {
+			counts[tag.Slug] = tag.PostCount
+		}
+		return counts
+	}
+
+	// Names are normalized and repeats dropped
+	w := createPost("Tagged Post", []string{"Gopherology", " gopherology ", "Web  Crafting", "<b>Rock & Roll</b>"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var first domain.PostResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &first))
+	if assert.Len(t, first.Tags, 3) {
+		assert.Equal(t, "gopherology", first.Tags[0].Name)
+		assert.Equal(t, "web crafting", first.Tags[1].Name)
+		assert.Equal(t, "web-crafting", first.Tags[1].Slug)
+		assert.Equal(t, "rock &amp; roll", first.Tags[2].Name)
+		assert.Equal(t, "rock-roll", first.Tags[2].Slug)
+	}
+	// Drafts do not count
+	assert.NotContains(t, tagCounts(), "gopherology")
+	suite.publishPost(first.PostID)
+
+	w = createPost("Another Tagged Post", []string{"GOPHEROLOGY"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var second domain.PostResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &second))
+	if assert.Len(t, second.Tags, 1) {
+		assert.Equal(t, first.Tags[0].ID, second.Tags[0].ID)
+	}
+	suite.publishPost(second.PostID)
+	counts := tagCounts()
+	assert.Equal(t, 2, counts["gopherology"])
+	assert.Equal(t, 1, counts["web-crafting"])
+
+	// Updates without tags carry them over to the new draft
+	updated := suite.updatePost(first.PostID, domain.UpdatePostDTO{Title: "Tagged Post", Content: "<p>edited</p>"})
+	assert.Len(t, updated.Tags, 3)
+	updated = suite.updatePost(first.PostID, domain.UpdatePostDTO{Title: "Tagged Post", Content: "<p>edited</p>", Tags: []string{}})
+	assert.Empty(t, updated.Tags)
+	// The published version keeps its tags until the draft is published
+	assert.Equal(t, 1, tagCounts()["web-crafting"])
+
+	req, err := http.NewRequest(http.MethodGet, "/tags/gopherology/posts", nil)
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var tagPosts domain.TagPostListResponseDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &tagPosts))
+	assert.Equal(t, "gopherology", tagPosts.Tag.Name)
+	if assert.Len(t, tagPosts.Posts, 2) {
+		assert.Equal(t, second.PostID, tagPosts.Posts[0].ID)
+		assert.Equal(t, first.PostID, tagPosts.Posts[1].ID)
+		assert.Len(t, tagPosts.Posts[1].Tags, 3)
+	}
+
+	req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/tags/%s/posts", "no-such-tag"), nil)
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	assert.Equal(t, http.StatusBadRequest, createPost("Badly Tagged Post", []string{"!!!"}).Code)
+	// Escaping counts towards the length limit
+	assert.Equal(t, http.StatusBadRequest, createPost("Escaped Tag Post", []string{"tags " + strings.Repeat("&", 20)}).Code)
+	// Short names can still expand into slugs that are too long
+	assert.Equal(t, http.StatusBadRequest, createPost("Long Slug Tag Post", []string{strings.Repeat("\uFDFA", 10) + strings.Repeat("\u05E9\u05BC\u05C1", 10)}).Code)
+}